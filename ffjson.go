@@ -34,6 +34,9 @@ var goCmdFlag = flag.String("go-cmd", "", "Path to go command; Useful for `goapp
 var importNameFlag = flag.String("import-name", "", "Override import name in case it cannot be detected.")
 var forceRegenerateFlag = flag.Bool("force-regenerate", false, "Regenerate every input file, without checking modification date.")
 var resetFields = flag.Bool("reset-fields", false, "When unmarshalling reset all fields missing in the JSON")
+var schemaFlag = flag.Bool("schema", false, "Also write a JSON Schema for every type to ${input}_${type}.schema.json.")
+var tsFlag = flag.String("ts", "", "Also write TypeScript declarations for all types to this path.")
+var codecsFlag = flag.String("codecs", "", "Read the codecs used for all values of external types from this file.")
 
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage of %s:\n\n", os.Args[0])
@@ -74,7 +77,7 @@ func main() {
 		importName = *importNameFlag
 	}
 
-	err := generator.GenerateFiles(goCmd, inputPath, outputPath, importName, *forceRegenerateFlag, *resetFields)
+	err := generator.GenerateFiles(goCmd, inputPath, outputPath, importName, *forceRegenerateFlag, *resetFields, *schemaFlag, *tsFlag, *codecsFlag)
 
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s:\n\n", err)
@@ -0,0 +1,108 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjson
+
+import (
+	"bytes"
+	"encoding/json"
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+)
+
+// Valid reports whether data is a valid JSON encoding, like json.Valid.
+// It does not allocate.
+func Valid(data []byte) bool {
+	fs := getLexer(data)
+	err := fs.CompactValue(nil)
+	if err == nil {
+		err = fs.ExpectEnd()
+	}
+	putLexer(fs)
+	return err == nil
+}
+
+// Compact appends to dst the JSON in src with insignificant whitespace
+// removed, like json.Compact. Strings are copied as they are.
+// On error, dst is left as it was.
+func Compact(dst *bytes.Buffer, src []byte) error {
+	n := dst.Len()
+	err := compact(dst, src)
+	if err != nil {
+		dst.Truncate(n)
+	}
+	return err
+}
+
+// CompactBuf is Compact for an EncodingBuffer, such as the buffer given to
+// MarshalJSONBuf, which makes it cheap to embed JSON from elsewhere in
+// generated output. On error, dst may hold part of the output.
+func CompactBuf(dst fflib.EncodingBuffer, src []byte) error {
+	return compact(dst, src)
+}
+
+func compact(dst fflib.JsonStringWriter, src []byte) error {
+	fs := getLexer(src)
+	defer putLexer(fs)
+	err := fs.CompactValue(dst)
+	if err != nil {
+		return err
+	}
+	return fs.ExpectEnd()
+}
+
+// Indent appends to dst an indented form of the JSON in src, like
+// json.Indent. Each element in a JSON object or array begins on a new line
+// beginning with prefix, followed by one or more copies of indent according
+// to the nesting depth. Leading whitespace in src is dropped, trailing
+// whitespace is kept like json.Indent keeps it. On error, dst is left as
+// it was.
+func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
+	n := dst.Len()
+	fs := getLexer(src)
+	defer putLexer(fs)
+
+	ib := fflib.NewIndentBuffer(dst, prefix, indent)
+	err := fs.CompactValue(ib)
+	end := fs.InputOffset()
+	if err == nil {
+		err = fs.ExpectEnd()
+	}
+	if err != nil {
+		dst.Truncate(n)
+		return err
+	}
+	ib.Flush()
+	indentTail(dst, src[end:], prefix, indent)
+	return nil
+}
+
+// indentTail appends the whitespace after the value to dst. Versions of
+// encoding/json differ in whether they indent after newlines in it, so
+// json.Indent handles those.
+func indentTail(dst *bytes.Buffer, tail []byte, prefix, indent string) {
+	if bytes.IndexByte(tail, '\n') < 0 {
+		dst.Write(tail)
+		return
+	}
+	var buf bytes.Buffer
+	err := json.Indent(&buf, append([]byte{'0'}, tail...), prefix, indent)
+	if err != nil || buf.Len() == 0 {
+		dst.Write(tail)
+		return
+	}
+	dst.Write(buf.Bytes()[1:])
+}
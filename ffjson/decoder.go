@@ -18,6 +18,7 @@ package ffjson
  */
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	fflib "github.com/pquerna/ffjson/fflib/v1"
@@ -29,7 +30,16 @@ import (
 // This is a reusable decoder.
 // This should not be used by more than one goroutine at the time.
 type Decoder struct {
-	fs *fflib.FFLexer
+	fs        *fflib.FFLexer
+	useNumber bool
+
+	// Input of More and Next. The lexer is at offset-base in data[base:],
+	// unless a value was decoded with encoding/json since it was reset.
+	data   []byte
+	base   int
+	offset int
+	synced bool
+	err    error
 }
 
 // NewDecoder returns a reusable Decoder.
@@ -37,31 +47,89 @@ func NewDecoder() *Decoder {
 	return &Decoder{}
 }
 
+// UseNumber causes the Decoder to unmarshal a number into an interface{} as a
+// json.Number instead of as a float64, both in generated code and when
+// falling back to encoding/json.
+func (d *Decoder) UseNumber() {
+	d.useNumber = true
+}
+
+func (d *Decoder) reset(data []byte) {
+	if d.fs == nil {
+		d.fs = fflib.NewFFLexer(data)
+	} else {
+		d.fs.Reset(data)
+	}
+	d.fs.UseNumber = d.useNumber
+}
+
+func (d *Decoder) resetReader(r io.Reader) {
+	if d.fs == nil {
+		d.fs = fflib.NewFFLexerReader(r)
+	} else {
+		d.fs.ResetReader(r)
+	}
+	d.fs.UseNumber = d.useNumber
+}
+
 // Decode the data in the supplied data slice.
+// Like json.Unmarshal, only whitespace may follow the value.
 func (d *Decoder) Decode(data []byte, v interface{}) error {
 	f, ok := v.(unmarshalFaster)
 	if ok {
-		if d.fs == nil {
-			d.fs = fflib.NewFFLexer(data)
-		} else {
-			d.fs.Reset(data)
+		d.reset(data)
+		err := f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start)
+		if err != nil {
+			return err
+		}
+		return d.fs.ExpectEnd()
+	}
+
+	if c := pointedCodec(v); c != nil {
+		d.reset(data)
+		err := decodeCodec(d.fs, c, v)
+		if err != nil {
+			return err
 		}
-		return f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start)
+		return d.fs.ExpectEnd()
 	}
 
 	um, ok := v.(json.Unmarshaler)
 	if ok {
 		return um.UnmarshalJSON(data)
 	}
+	if d.useNumber {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		return dec.Decode(v)
+	}
 	return json.Unmarshal(data, v)
 }
 
 // Decode the data from the supplied reader.
-// You should expect that data is read into memory before it is decoded.
+// Types with ffjson generated code are decoded as the data is read, and only
+// need memory for the largest single token, so arbitrarily large input can be
+// decoded. The decoder may read past the end of the JSON value.
+// For other types you should expect that data is read into memory before
+// it is decoded.
 func (d *Decoder) DecodeReader(r io.Reader, v interface{}) error {
-	_, ok := v.(unmarshalFaster)
-	_, ok2 := v.(json.Unmarshaler)
-	if ok || ok2 {
+	f, ok := v.(unmarshalFaster)
+	if ok {
+		d.resetReader(r)
+		err := f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start)
+		d.fs.ResetReader(nil)
+		return err
+	}
+
+	if c := pointedCodec(v); c != nil {
+		d.resetReader(r)
+		err := decodeCodec(d.fs, c, v)
+		d.fs.ResetReader(nil)
+		return err
+	}
+
+	_, ok = v.(json.Unmarshaler)
+	if ok {
 		data, err := ioutil.ReadAll(r)
 		if err != nil {
 			return err
@@ -70,6 +138,9 @@ func (d *Decoder) DecodeReader(r io.Reader, v interface{}) error {
 		return d.Decode(data, v)
 	}
 	dec := json.NewDecoder(r)
+	if d.useNumber {
+		dec.UseNumber()
+	}
 	return dec.Decode(v)
 }
 
@@ -83,10 +154,83 @@ func (d *Decoder) DecodeFast(data []byte, v interface{}) error {
 	if !ok {
 		return errors.New("ffjson unmarshal not available for type " + reflect.TypeOf(v).String())
 	}
-	if d.fs == nil {
-		d.fs = fflib.NewFFLexer(data)
-	} else {
-		d.fs.Reset(data)
+	d.reset(data)
+	err := f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start)
+	if err != nil {
+		return err
+	}
+	return d.fs.ExpectEnd()
+}
+
+// Reset sets data as the input of More and Next, which decode a sequence
+// of concatenated values from it, optionally separated by whitespace,
+// like json.Decoder does for a stream.
+func (d *Decoder) Reset(data []byte) {
+	d.reset(data)
+	d.data = data
+	d.base = 0
+	d.offset = 0
+	d.synced = true
+	d.err = nil
+}
+
+// More reports whether there is another value left in the input given to
+// Reset. It returns false after Next returned an error.
+func (d *Decoder) More() bool {
+	return d.err == nil && d.start() < len(d.data)
+}
+
+// start returns the offset of the next value, after any whitespace.
+func (d *Decoder) start() int {
+	i := d.offset
+	for i < len(d.data) && fflib.IsSpace(d.data[i]) {
+		i++
+	}
+	return i
+}
+
+// Next decodes the next value in the input given to Reset into v.
+// It returns io.EOF when there are no values left. After an error,
+// the rest of the input is not decoded and the error is returned again.
+func (d *Decoder) Next(v interface{}) error {
+	if d.err != nil {
+		return d.err
+	}
+	start := d.start()
+	if start == len(d.data) {
+		return io.EOF
+	}
+
+	f, ok := v.(unmarshalFaster)
+	if !ok {
+		dec := json.NewDecoder(bytes.NewReader(d.data[start:]))
+		if d.useNumber {
+			dec.UseNumber()
+		}
+		d.err = dec.Decode(v)
+		if d.err != nil {
+			return d.err
+		}
+		d.offset = start + int(dec.InputOffset())
+		d.synced = false
+		return nil
+	}
+
+	if !d.synced {
+		d.reset(d.data[start:])
+		d.base = start
+		d.synced = true
 	}
-	return f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start)
+	d.err = f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start)
+	if d.err != nil {
+		return d.err
+	}
+	d.offset = d.base + d.fs.InputOffset()
+	return nil
+}
+
+// InputOffset returns the offset in the input given to Reset just after
+// the last value decoded by Next.
+func (d *Decoder) InputOffset() int {
+	return d.offset
 }
@@ -29,9 +29,15 @@ import (
 // It allows to encode many objects to a single writer.
 // This should not be used by more than one goroutine at the time.
 type Encoder struct {
-	buf fflib.Buffer
-	w   io.Writer
-	enc *json.Encoder
+	buf      fflib.Buffer
+	w        io.Writer
+	enc      *json.Encoder
+	chunked  *fflib.ChunkedBuffer
+	indented *fflib.IndentBuffer
+
+	indentPrefix string
+	indentValue  string
+	escaping     fflib.Escaping
 }
 
 // SetEscapeHTML specifies whether problematic HTML characters
@@ -41,8 +47,82 @@ type Encoder struct {
 //
 // In non-HTML settings where the escaping interferes with the readability
 // of the output, SetEscapeHTML(false) disables this behavior.
+// For generated code this is the same as SetEscaping with fflib.EscapeHTML
+// or fflib.EscapeMinimal, keeping fflib.RejectInvalidUTF8 if it was set.
 func (enc *Encoder) SetEscapeHTML(on bool) {
-	enc.enc.SetEscapeHTML(on)
+	mode := fflib.EscapeMinimal
+	if on {
+		mode = fflib.EscapeHTML
+	}
+	enc.SetEscaping(mode | enc.escaping&fflib.RejectInvalidUTF8)
+}
+
+// SetEscaping selects how strings are escaped, see fflib.Escaping.
+// With fflib.RejectInvalidUTF8, Encode returns fflib.ErrInvalidUTF8 for
+// values with strings that are not valid UTF-8.
+// The encoding/json fallback only knows whether to escape HTML, it always
+// escapes U+2028 and U+2029, and replaces invalid UTF-8 without an error.
+func (enc *Encoder) SetEscaping(e fflib.Escaping) {
+	enc.escaping = e
+	enc.enc.SetEscapeHTML(e.Mode() != fflib.EscapeMinimal)
+}
+
+// SetIndent instructs the encoder to format each subsequent encoded
+// value as if indented by the package-level function Indent(dst, src, prefix, indent).
+// Calling SetIndent("", "") disables indentation.
+// Generated code is indented as it is written, see MarshalIndent.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.indentPrefix = prefix
+	e.indentValue = indent
+	e.indented = nil
+	e.enc.SetIndent(prefix, indent)
+}
+
+// SetChunkSize makes Encode write the output of ffjson generated code to the
+// writer in chunks of about n bytes while the value is being encoded, instead
+// of all at once when it is done. This bounds the memory needed to encode
+// very large values, but if encoding fails, part of the value may already have
+// been written. n <= 0 restores the default of writing everything at once.
+func (e *Encoder) SetChunkSize(n int) {
+	e.indented = nil
+	if n <= 0 {
+		e.chunked = nil
+		return
+	}
+	e.chunked = fflib.NewChunkedBuffer(e.w, n)
+}
+
+// target returns the buffer generated code writes to, indenting into
+// the output buffer if SetIndent was used.
+func (e *Encoder) target(out fflib.EncodingBuffer) fflib.EncodingBuffer {
+	if e.indentPrefix == "" && e.indentValue == "" {
+		return out
+	}
+	if e.indented == nil {
+		e.indented = fflib.NewIndentBuffer(out, e.indentPrefix, e.indentValue)
+	}
+	e.indented.Reset()
+	return e.indented
+}
+
+// marshal runs generated code, writing to out.
+func (e *Encoder) marshal(f marshalerFaster, out fflib.EncodingBuffer) error {
+	buf := e.target(out)
+	eb, ok := buf.(fflib.EscapingBuffer)
+	if ok {
+		eb.SetEscaping(e.escaping)
+	}
+	err := f.MarshalJSONBuf(buf)
+	if err == nil && ok {
+		err = eb.EscapeError()
+	}
+	if err != nil {
+		return err
+	}
+	if e.indented != nil {
+		e.indented.Flush()
+	}
+	return nil
 }
 
 // NewEncoder returns a reusable Encoder.
@@ -56,10 +136,18 @@ func NewEncoder(w io.Writer) *Encoder {
 // When the function returns the output has been
 // written to the stream.
 func (e *Encoder) Encode(v interface{}) error {
-	f, ok := v.(marshalerFaster)
+	f, ok := fastMarshaler(v)
+	if ok && e.chunked != nil {
+		e.chunked.Reset()
+		err := e.marshal(f, e.chunked)
+		if err != nil {
+			return err
+		}
+		return e.chunked.Flush()
+	}
 	if ok {
 		e.buf.Reset()
-		err := f.MarshalJSONBuf(&e.buf)
+		err := e.marshal(f, &e.buf)
 		if err != nil {
 			return err
 		}
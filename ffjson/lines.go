@@ -0,0 +1,186 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"io"
+	"sync"
+)
+
+var lineBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(fflib.Buffer)
+	},
+}
+
+// LineEncoder writes values as JSON Lines (also known as NDJSON):
+// every value is written compactly on its own line, followed by '\n'.
+// This should not be used by more than one goroutine at the time.
+type LineEncoder struct {
+	w io.Writer
+}
+
+// NewLineEncoder returns a LineEncoder writing to w.
+func NewLineEncoder(w io.Writer) *LineEncoder {
+	return &LineEncoder{w: w}
+}
+
+// Encode writes v as a single line, with one call to Write on the
+// underlying writer. Values without ffjson generated code fall back
+// to encoding/json.
+func (e *LineEncoder) Encode(v interface{}) error {
+	buf := lineBufferPool.Get().(*fflib.Buffer)
+	defer lineBufferPool.Put(buf)
+	buf.Reset()
+
+	f, ok := v.(marshalerFaster)
+	if ok {
+		err := f.MarshalJSONBuf(buf)
+		if err != nil {
+			return err
+		}
+	} else {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+
+	if bytes.IndexByte(buf.Bytes(), '\n') >= 0 {
+		// A custom MarshalJSON produced indented output.
+		var compact bytes.Buffer
+		err := json.Compact(&compact, buf.Bytes())
+		if err != nil {
+			return err
+		}
+		buf.Reset()
+		buf.Write(compact.Bytes())
+	}
+
+	buf.WriteByte('\n')
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// LineError is returned by LineDecoder when a line cannot be decoded.
+// Decoding can continue with the next line.
+type LineError struct {
+	// Line is the 1-based line number in the input.
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("ffjson: line %d: %v", e.Line, e.Err)
+}
+
+// LineDecoder reads values from JSON Lines (also known as NDJSON) input,
+// one value per line. Empty lines are skipped. The same lexer is reused
+// for every line.
+// This should not be used by more than one goroutine at the time.
+type LineDecoder struct {
+	r    *bufio.Reader
+	dec  Decoder
+	buf  []byte
+	line []byte
+	more bool
+	n    int
+	err  error
+}
+
+// NewLineDecoder returns a LineDecoder reading from r.
+func NewLineDecoder(r io.Reader) *LineDecoder {
+	return &LineDecoder{r: bufio.NewReader(r)}
+}
+
+// UseNumber causes the LineDecoder to unmarshal a number into an interface{}
+// as a json.Number instead of as a float64.
+func (d *LineDecoder) UseNumber() {
+	d.dec.UseNumber()
+}
+
+// More reports whether there is another line to decode.
+// If reading the input fails, More returns false and Next returns the error.
+func (d *LineDecoder) More() bool {
+	for !d.more && d.err == nil {
+		d.line, d.err = d.readLine()
+		if d.err != nil {
+			break
+		}
+		d.n++
+		d.more = len(bytes.TrimSpace(d.line)) > 0
+	}
+	return d.more
+}
+
+// Next decodes the next line into v. It returns io.EOF when the input
+// is exhausted, and a *LineError if the line is not a valid value for v.
+func (d *LineDecoder) Next(v interface{}) error {
+	if !d.More() {
+		return d.err
+	}
+	d.more = false
+
+	err := d.decode(d.line, v)
+	if err != nil {
+		return &LineError{Line: d.n, Err: err}
+	}
+	return nil
+}
+
+func (d *LineDecoder) decode(line []byte, v interface{}) error {
+	f, ok := v.(unmarshalFaster)
+	if !ok {
+		return d.dec.Decode(line, v)
+	}
+
+	d.dec.reset(line)
+	err := f.UnmarshalJSONFFLexer(d.dec.fs, fflib.FFParse_map_start)
+	if err != nil {
+		return err
+	}
+	return d.dec.fs.ExpectEnd()
+}
+
+// readLine returns the next line, without the line ending.
+// The line is only valid until the next call.
+func (d *LineDecoder) readLine() ([]byte, error) {
+	d.buf = d.buf[:0]
+	for {
+		frag, err := d.r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			d.buf = append(d.buf, frag...)
+			continue
+		}
+		if len(d.buf) > 0 {
+			d.buf = append(d.buf, frag...)
+			frag = d.buf
+		}
+		if err == io.EOF && len(frag) > 0 {
+			// The last line does not need a line ending.
+			err = nil
+		}
+		return bytes.TrimSuffix(frag, []byte{'\n'}), err
+	}
+}
@@ -38,9 +38,11 @@ type unmarshalFaster interface {
 // Using this function will bypass the internal copying and parsing
 // the json library normally does, which greatly speeds up encoding time.
 // It is ok to call this function even if no ffjson code has been
-// generated for the data type you pass in the interface.
+// generated for the data type you pass in the interface. A codec
+// registered with fflib.RegisterCodec for the type of v, or the type
+// it points to, is used too.
 func Marshal(v interface{}) ([]byte, error) {
-	f, ok := v.(marshalerFaster)
+	f, ok := fastMarshaler(v)
 	if ok {
 		buf := fflib.Buffer{}
 		err := f.MarshalJSONBuf(&buf)
@@ -61,6 +63,59 @@ func Marshal(v interface{}) ([]byte, error) {
 	return json.Marshal(v)
 }
 
+// MarshalIndent is like Marshal, but indents the output like
+// json.MarshalIndent, and produces the same bytes.
+// Generated code is indented as it is written, without encoding
+// the value twice.
+func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	f, ok := fastMarshaler(v)
+	if ok {
+		buf := fflib.Buffer{}
+		ib := fflib.NewIndentBuffer(&buf, prefix, indent)
+		err := f.MarshalJSONBuf(ib)
+		if err != nil {
+			if b := buf.Bytes(); len(b) > 0 {
+				Pool(b)
+			}
+			return nil, err
+		}
+		ib.Flush()
+		return buf.Bytes(), nil
+	}
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+// MarshalAppend appends the JSON encoding of v to dst and returns the
+// extended slice, like the strconv Append functions. The memory belongs
+// to the caller, it is never taken from or handed to the pool.
+// On error dst is returned unchanged, apart from its spare capacity.
+// It is ok to call this function even if no ffjson code has been
+// generated for the data type you pass in the interface.
+func MarshalAppend(dst []byte, v interface{}) ([]byte, error) {
+	f, ok := fastMarshaler(v)
+	if ok {
+		buf := fflib.AppendBuffer(dst)
+		err := f.MarshalJSONBuf(&buf)
+		if err != nil {
+			return dst, err
+		}
+		return buf, nil
+	}
+
+	var b []byte
+	var err error
+	j, ok := v.(json.Marshaler)
+	if ok {
+		b, err = j.MarshalJSON()
+	} else {
+		b, err = json.Marshal(v)
+	}
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, b...), nil
+}
+
 // MarshalFast will marshal the data if fast marshal is available.
 // This function can be used if you want to be sure the fast
 // marshal is used or in testing.
@@ -80,12 +135,28 @@ func MarshalFast(v interface{}) ([]byte, error) {
 // The overhead of unmarshal is lower than on Marshal,
 // however this should still provide a speedup for your encoding.
 // It is ok to call this function even if no ffjson code has been
-// generated for the data type you pass in the interface.
+// generated for the data type you pass in the interface. A codec
+// registered with fflib.RegisterCodec for the type v points to is used too.
 func Unmarshal(data []byte, v interface{}) error {
 	f, ok := v.(unmarshalFaster)
 	if ok {
-		fs := fflib.NewFFLexer(data)
-		return f.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+		fs := getLexer(data)
+		defer putLexer(fs)
+		err := f.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+		if err != nil {
+			return err
+		}
+		return fs.ExpectEnd()
+	}
+
+	if c := pointedCodec(v); c != nil {
+		fs := getLexer(data)
+		defer putLexer(fs)
+		err := decodeCodec(fs, c, v)
+		if err != nil {
+			return err
+		}
+		return fs.ExpectEnd()
 	}
 
 	j, ok := v.(json.Unmarshaler)
@@ -107,3 +178,62 @@ func UnmarshalFast(data []byte, v interface{}) error {
 	}
 	return Unmarshal(data, v)
 }
+
+// decodeCodec reads the value v points to from fs with c.
+func decodeCodec(fs *fflib.FFLexer, c fflib.Codec, v interface{}) error {
+	tok := fs.Scan()
+	if tok == fflib.FFTok_error {
+		if fs.BigError != nil {
+			return fs.WrapErr(fs.BigError)
+		}
+		return fs.WrapErr(fs.Error.ToError())
+	}
+	err := c.DecodeValue(fs, tok, v)
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+	return nil
+}
+
+// codecValue writes a value with its registered codec, like generated code.
+type codecValue struct {
+	c fflib.Codec
+	v interface{}
+}
+
+func (cv codecValue) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	return cv.c.EncodeValue(buf, cv.v)
+}
+
+// fastMarshaler returns the generated code of v, or the codec registered for
+// the type of v or the type it points to. Nil pointers have no codec, they
+// are written as null by encoding/json.
+func fastMarshaler(v interface{}) (marshalerFaster, bool) {
+	if f, ok := v.(marshalerFaster); ok {
+		return f, true
+	}
+	if v == nil {
+		return nil, false
+	}
+	if c := fflib.LookupCodec(reflect.TypeOf(v)); c != nil {
+		return codecValue{c, v}, true
+	}
+	if c := pointedCodec(v); c != nil {
+		return codecValue{c, reflect.ValueOf(v).Elem().Interface()}, true
+	}
+	return nil, false
+}
+
+// pointedCodec returns the codec registered for the type v points to, or
+// nil, also if v is a nil pointer.
+func pointedCodec(v interface{}) fflib.Codec {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil
+	}
+	c := fflib.LookupCodec(t.Elem())
+	if c == nil || reflect.ValueOf(v).IsNil() {
+		return nil
+	}
+	return c
+}
@@ -0,0 +1,452 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjson
+
+import (
+	"errors"
+	"fmt"
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"io"
+	"strings"
+)
+
+// ErrPointerNotFound is returned by Get and GetScalar when there is no value
+// at the JSON Pointer.
+var ErrPointerNotFound = errors.New("ffjson: JSON pointer not found")
+
+// Get returns the value at an RFC 6901 JSON Pointer such as "/items/0/sku"
+// in data, as the raw JSON bytes. Only the path to the value is looked at,
+// everything else in the document is skipped without being decoded.
+// The returned slice points into data.
+//
+// If there is no value at pointer, Get returns ErrPointerNotFound without
+// allocating. To look up several pointers in the same document, use
+// CompilePointers.
+func Get(data []byte, pointer string) ([]byte, error) {
+	if err := checkPointer(pointer); err != nil {
+		return nil, err
+	}
+
+	fs := getLexer(data)
+	defer putLexer(fs)
+
+	tok := fs.Scan()
+	for p := pointer; p != ""; {
+		var seg string
+		seg, p = nextSegment(p)
+
+		var err error
+		switch tok {
+		case fflib.FFTok_left_bracket:
+			tok, err = findKey(fs, seg)
+		case fflib.FFTok_left_brace:
+			tok, err = findIndex(fs, parseIndex(seg))
+		case fflib.FFTok_string, fflib.FFTok_integer, fflib.FFTok_double,
+			fflib.FFTok_bool, fflib.FFTok_null:
+			// Scalars have no children.
+			err = ErrPointerNotFound
+		default:
+			err = lexError(fs, tok)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rawValue(fs, tok, data)
+}
+
+// GetScalar is Get for a string, number, bool or null value, which it
+// returns as a Token. Strings are unescaped, and only copied if they
+// contain escapes.
+func GetScalar(data []byte, pointer string) (Token, error) {
+	raw, err := Get(data, pointer)
+	if err != nil {
+		return Token{}, err
+	}
+
+	switch raw[0] {
+	case '"':
+		s, ok := fflib.UnquoteBytes(raw)
+		if !ok {
+			return Token{}, fmt.Errorf("ffjson: invalid string at %s", pointer)
+		}
+		return Token{Kind: TokenString, b: s}, nil
+	case 't', 'f':
+		return Token{Kind: TokenBool, b: raw}, nil
+	case 'n':
+		return Token{Kind: TokenNull, b: raw}, nil
+	case '{', '[':
+		return Token{}, fmt.Errorf("ffjson: value at %s is not a scalar", pointer)
+	}
+	return Token{Kind: TokenNumber, b: raw}, nil
+}
+
+// PointerSet is a set of compiled JSON Pointers, which are looked up in a
+// document together, in one pass over it. It is safe for concurrent use.
+type PointerSet struct {
+	root pointerNode
+	n    int
+}
+
+// pointerNode is a segment of the pointers in a PointerSet. The pointers
+// that end at it are its targets.
+type pointerNode struct {
+	name     string
+	index    int
+	targets  []int
+	children []*pointerNode
+}
+
+// CompilePointers compiles RFC 6901 JSON Pointers into a PointerSet.
+func CompilePointers(pointers ...string) (*PointerSet, error) {
+	ps := &PointerSet{n: len(pointers)}
+	for i, pointer := range pointers {
+		if err := checkPointer(pointer); err != nil {
+			return nil, err
+		}
+
+		n := &ps.root
+		for p := pointer; p != ""; {
+			var seg string
+			seg, p = nextSegment(p)
+			n = n.child(unescapeSegment(seg))
+		}
+		n.targets = append(n.targets, i)
+	}
+	return ps, nil
+}
+
+// Len returns the number of pointers in the set.
+func (ps *PointerSet) Len() int {
+	return ps.n
+}
+
+// Get looks up all pointers of the set in data, and appends their values
+// to dst as raw JSON, in the order they were given to CompilePointers.
+// Pointers that are not found get a nil value. Only the paths to the
+// values are looked at, and data is only read up to the last value found.
+// The values point into data.
+func (ps *PointerSet) Get(dst [][]byte, data []byte) ([][]byte, error) {
+	base := len(dst)
+	for i := 0; i < ps.n; i++ {
+		dst = append(dst, nil)
+	}
+	if ps.n == 0 {
+		return dst, nil
+	}
+
+	fs := getLexer(data)
+	defer putLexer(fs)
+
+	w := pointerWalk{fs: fs, data: data, out: dst[base:], left: ps.n}
+	err := w.walk(&ps.root, fs.Scan())
+	return dst, err
+}
+
+// child returns the child of n for the segment name, adding it if needed.
+func (n *pointerNode) child(name string) *pointerNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &pointerNode{name: name, index: parseIndex(name)}
+	n.children = append(n.children, c)
+	return c
+}
+
+func (n *pointerNode) childKey(key []byte) *pointerNode {
+	for _, c := range n.children {
+		if c.name == string(key) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *pointerNode) childIndex(index int) *pointerNode {
+	for _, c := range n.children {
+		if c.index == index {
+			return c
+		}
+	}
+	return nil
+}
+
+// pointerWalk is the state of PointerSet.Get.
+type pointerWalk struct {
+	fs   *fflib.FFLexer
+	data []byte
+	out  [][]byte
+	// targets not found yet
+	left int
+}
+
+// walk moves past the value starting with tok, looking up the pointers
+// below n in it.
+func (w *pointerWalk) walk(n *pointerNode, tok fflib.FFTok) error {
+	start := w.fs.TokenStart()
+
+	var err error
+	switch {
+	case len(n.children) > 0 && tok == fflib.FFTok_left_bracket:
+		err = w.object(n)
+	case len(n.children) > 0 && tok == fflib.FFTok_left_brace:
+		err = w.array(n)
+	default:
+		err = skipValue(w.fs, tok)
+	}
+	if err != nil || w.left == 0 {
+		return err
+	}
+
+	for _, t := range n.targets {
+		w.out[t] = w.data[start:w.fs.InputOffset()]
+		w.left--
+	}
+	return nil
+}
+
+func (w *pointerWalk) object(n *pointerNode) error {
+	fs := w.fs
+	tok := fs.Scan()
+	if tok == fflib.FFTok_right_bracket {
+		return nil
+	}
+	for {
+		if tok != fflib.FFTok_string {
+			return lexError(fs, tok)
+		}
+		c := n.childKey(fs.Output.Bytes())
+		if tok = fs.Scan(); tok != fflib.FFTok_colon {
+			return lexError(fs, tok)
+		}
+
+		tok = fs.Scan()
+		if c != nil {
+			if err := w.walk(c, tok); err != nil || w.left == 0 {
+				return err
+			}
+		} else if err := skipValue(fs, tok); err != nil {
+			return err
+		}
+
+		switch tok = fs.Scan(); tok {
+		case fflib.FFTok_comma:
+			tok = fs.Scan()
+		case fflib.FFTok_right_bracket:
+			return nil
+		default:
+			return lexError(fs, tok)
+		}
+	}
+}
+
+func (w *pointerWalk) array(n *pointerNode) error {
+	fs := w.fs
+	tok := fs.Scan()
+	if tok == fflib.FFTok_right_brace {
+		return nil
+	}
+	for i := 0; ; i++ {
+		if c := n.childIndex(i); c != nil {
+			if err := w.walk(c, tok); err != nil || w.left == 0 {
+				return err
+			}
+		} else if err := skipValue(fs, tok); err != nil {
+			return err
+		}
+
+		switch tok = fs.Scan(); tok {
+		case fflib.FFTok_comma:
+			tok = fs.Scan()
+		case fflib.FFTok_right_brace:
+			return nil
+		default:
+			return lexError(fs, tok)
+		}
+	}
+}
+
+// findKey moves past the keys of the object that was just opened up to
+// key, given as an escaped pointer segment, and returns the first token of
+// its value.
+func findKey(fs *fflib.FFLexer, seg string) (fflib.FFTok, error) {
+	tok := fs.Scan()
+	if tok == fflib.FFTok_right_bracket {
+		return tok, ErrPointerNotFound
+	}
+	for {
+		if tok != fflib.FFTok_string {
+			return tok, lexError(fs, tok)
+		}
+		match := segmentEqual(seg, fs.Output.Bytes())
+		if tok = fs.Scan(); tok != fflib.FFTok_colon {
+			return tok, lexError(fs, tok)
+		}
+
+		tok = fs.Scan()
+		if match {
+			return tok, nil
+		}
+		if err := skipValue(fs, tok); err != nil {
+			return tok, err
+		}
+
+		switch tok = fs.Scan(); tok {
+		case fflib.FFTok_comma:
+			tok = fs.Scan()
+		case fflib.FFTok_right_bracket:
+			return tok, ErrPointerNotFound
+		default:
+			return tok, lexError(fs, tok)
+		}
+	}
+}
+
+// findIndex moves past the elements of the array that was just opened up
+// to index, and returns the first token of that element.
+func findIndex(fs *fflib.FFLexer, index int) (fflib.FFTok, error) {
+	if index < 0 {
+		return fflib.FFTok_error, ErrPointerNotFound
+	}
+	tok := fs.Scan()
+	if tok == fflib.FFTok_right_brace {
+		return tok, ErrPointerNotFound
+	}
+	for i := 0; ; i++ {
+		if i == index {
+			return tok, nil
+		}
+		if err := skipValue(fs, tok); err != nil {
+			return tok, err
+		}
+
+		switch tok = fs.Scan(); tok {
+		case fflib.FFTok_comma:
+			tok = fs.Scan()
+		case fflib.FFTok_right_brace:
+			return tok, ErrPointerNotFound
+		default:
+			return tok, lexError(fs, tok)
+		}
+	}
+}
+
+// skipValue moves past the value starting with tok.
+func skipValue(fs *fflib.FFLexer, tok fflib.FFTok) error {
+	switch tok {
+	case fflib.FFTok_left_bracket, fflib.FFTok_left_brace,
+		fflib.FFTok_string, fflib.FFTok_integer, fflib.FFTok_double,
+		fflib.FFTok_bool, fflib.FFTok_null:
+		if err := fs.SkipField(tok); err != nil {
+			return fs.WrapErr(err)
+		}
+		return nil
+	}
+	return lexError(fs, tok)
+}
+
+// rawValue skips the value starting with tok, and returns its bytes.
+func rawValue(fs *fflib.FFLexer, tok fflib.FFTok, data []byte) ([]byte, error) {
+	start := fs.TokenStart()
+	if err := skipValue(fs, tok); err != nil {
+		return nil, err
+	}
+	return data[start:fs.InputOffset()], nil
+}
+
+func lexError(fs *fflib.FFLexer, tok fflib.FFTok) error {
+	switch {
+	case tok == fflib.FFTok_eof, fs.BigError == io.EOF:
+		return fs.WrapErr(io.ErrUnexpectedEOF)
+	case fs.BigError != nil:
+		return fs.WrapErr(fs.BigError)
+	case tok == fflib.FFTok_error:
+		return fs.WrapErr(fs.Error.ToError())
+	}
+	return fs.WrapErr(fmt.Errorf("ffjson: unexpected %v", tok))
+}
+
+// checkPointer returns an error unless pointer is empty, for the whole
+// document, or a sequence of segments each starting with '/', with '~'
+// only in the escapes "~0" for '~' and "~1" for '/'.
+func checkPointer(pointer string) error {
+	if pointer != "" && pointer[0] != '/' {
+		return fmt.Errorf("ffjson: JSON pointer %q does not start with '/'", pointer)
+	}
+	for i := 0; i < len(pointer); i++ {
+		if pointer[i] == '~' && (i+1 == len(pointer) || pointer[i+1] != '0' && pointer[i+1] != '1') {
+			return fmt.Errorf("ffjson: invalid escape in JSON pointer %q", pointer)
+		}
+	}
+	return nil
+}
+
+// nextSegment splits the first, still escaped, segment off p.
+func nextSegment(p string) (seg, rest string) {
+	p = p[1:]
+	if i := strings.IndexByte(p, '/'); i >= 0 {
+		return p[:i], p[i:]
+	}
+	return p, ""
+}
+
+// unescapeSegment replaces the escapes in a pointer segment.
+func unescapeSegment(seg string) string {
+	if strings.IndexByte(seg, '~') < 0 {
+		return seg
+	}
+	return strings.Replace(strings.Replace(seg, "~1", "/", -1), "~0", "~", -1)
+}
+
+// segmentEqual reports whether the escaped pointer segment seg is key.
+func segmentEqual(seg string, key []byte) bool {
+	j := 0
+	for i := 0; i < len(seg); i++ {
+		c := seg[i]
+		if c == '~' {
+			i++
+			if seg[i] == '1' {
+				c = '/'
+			}
+		}
+		if j == len(key) || key[j] != c {
+			return false
+		}
+		j++
+	}
+	return j == len(key)
+}
+
+// parseIndex returns the array index a pointer segment stands for, or -1 if
+// it is not one. RFC 6901 does not allow leading zeros, and "-" stands for
+// the element after the last one, which never exists here.
+func parseIndex(seg string) int {
+	if seg == "" || len(seg) > 1 && seg[0] == '0' || len(seg) > 9 {
+		return -1
+	}
+	n := 0
+	for i := 0; i < len(seg); i++ {
+		if seg[i] < '0' || seg[i] > '9' {
+			return -1
+		}
+		n = n*10 + int(seg[i]-'0')
+	}
+	return n
+}
@@ -19,6 +19,8 @@ package ffjson
 
 import (
 	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"io"
+	"sync"
 )
 
 // Send a buffer to the Pool to reuse for other instances.
@@ -31,3 +33,65 @@ import (
 func Pool(b []byte) {
 	fflib.Pool(b)
 }
+
+// Lexers used by the package-level functions, which are safe to call from
+// several goroutines at the time.
+var lexerPool = sync.Pool{
+	New: func() interface{} {
+		return fflib.NewFFLexer(nil)
+	},
+}
+
+func getLexer(data []byte) *fflib.FFLexer {
+	fs := lexerPool.Get().(*fflib.FFLexer)
+	fs.Reset(data)
+	return fs
+}
+
+func putLexer(fs *fflib.FFLexer) {
+	// Don't keep the input alive.
+	fs.Reset(nil)
+	lexerPool.Put(fs)
+}
+
+// DecoderPool is a pool of reusable Decoders. Unlike a Decoder, it can be
+// used from several goroutines at the time, for example by HTTP handlers.
+// The zero value is ready to use.
+type DecoderPool struct {
+	pool sync.Pool
+}
+
+// Get returns a Decoder from the pool, or a new one if the pool is empty.
+// Hand it back with Put when done.
+func (p *DecoderPool) Get() *Decoder {
+	d, ok := p.pool.Get().(*Decoder)
+	if !ok {
+		d = NewDecoder()
+	}
+	return d
+}
+
+// Put returns d to the pool. d must not be used after that.
+func (p *DecoderPool) Put(d *Decoder) {
+	d.useNumber = false
+	d.Reset(nil)
+	p.pool.Put(d)
+}
+
+// Decode decodes data into v with a Decoder from the pool,
+// see Decoder.Decode.
+func (p *DecoderPool) Decode(data []byte, v interface{}) error {
+	d := p.Get()
+	err := d.Decode(data, v)
+	p.Put(d)
+	return err
+}
+
+// DecodeReader decodes the data read from r into v with a Decoder from the
+// pool, see Decoder.DecodeReader.
+func (p *DecoderPool) DecodeReader(r io.Reader, v interface{}) error {
+	d := p.Get()
+	err := d.DecodeReader(r, v)
+	p.Put(d)
+	return err
+}
@@ -0,0 +1,423 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjson
+
+import (
+	"encoding/json"
+	"fmt"
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"io"
+)
+
+// TokenKind is the type of a Token.
+type TokenKind int
+
+const (
+	TokenObjectStart TokenKind = iota + 1 // {
+	TokenObjectEnd                        // }
+	TokenArrayStart                       // [
+	TokenArrayEnd                         // ]
+	TokenKey                              // an object key
+	TokenString                           // a string value
+	TokenNumber                           // a number
+	TokenBool                             // true or false
+	TokenNull                             // null
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenObjectStart:
+		return "object start"
+	case TokenObjectEnd:
+		return "object end"
+	case TokenArrayStart:
+		return "array start"
+	case TokenArrayEnd:
+		return "array end"
+	case TokenKey:
+		return "key"
+	case TokenString:
+		return "string"
+	case TokenNumber:
+		return "number"
+	case TokenBool:
+		return "bool"
+	case TokenNull:
+		return "null"
+	}
+	return fmt.Sprintf("TokenKind(%d)", int(k))
+}
+
+// Token is a single JSON token returned by TokenReader.
+// The bytes of a token are not copied out of the lexer: they are only
+// valid until the next call to the TokenReader, use String or copy them
+// to keep them.
+type Token struct {
+	Kind TokenKind
+	b    []byte
+}
+
+// Bytes returns the unescaped bytes of a key or string, or the literal
+// bytes of a number, bool or null.
+func (t Token) Bytes() []byte {
+	switch t.Kind {
+	case TokenObjectStart:
+		return []byte{'{'}
+	case TokenObjectEnd:
+		return []byte{'}'}
+	case TokenArrayStart:
+		return []byte{'['}
+	case TokenArrayEnd:
+		return []byte{']'}
+	}
+	return t.b
+}
+
+// String returns a copy of Bytes as a string.
+func (t Token) String() string {
+	return string(t.Bytes())
+}
+
+// Bool returns the value of a bool token.
+func (t Token) Bool() bool {
+	return t.Kind == TokenBool && len(t.b) == 4
+}
+
+// Int parses a number token as an int64.
+func (t Token) Int() (int64, error) {
+	if t.Kind != TokenNumber {
+		return 0, fmt.Errorf("ffjson: cannot parse %v token as a number", t.Kind)
+	}
+	return fflib.ParseInt(t.b, 10, 64)
+}
+
+// Float parses a number token as a float64.
+func (t Token) Float() (float64, error) {
+	if t.Kind != TokenNumber {
+		return 0, fmt.Errorf("ffjson: cannot parse %v token as a number", t.Kind)
+	}
+	return fflib.ParseFloat(t.b, 64)
+}
+
+// Number returns a number token as a json.Number.
+func (t Token) Number() json.Number {
+	return json.Number(t.b)
+}
+
+// Where a TokenReader is in the JSON grammar, as in encoding/json's Decoder.
+const (
+	tokenTopValue    = iota // a top-level value
+	tokenArrayStart         // after '[', a value or ']'
+	tokenArrayValue         // after a value in an array, ',' or ']'
+	tokenArrayComma         // after ',' in an array, a value
+	tokenObjectStart        // after '{', a key or '}'
+	tokenObjectKey          // after a key, ':'
+	tokenObjectColon        // after ':', a value
+	tokenObjectValue        // after a value in an object, ',' or '}'
+	tokenObjectComma        // after ',' in an object, a key
+)
+
+// TokenReader reads JSON input one token at a time, like
+// encoding/json's Decoder.Token, using the ffjson lexer. It checks that the
+// tokens form valid JSON, and returns commas and colons only implicitly.
+// Several top-level values may follow each other in the input.
+// This should not be used by more than one goroutine at the time.
+type TokenReader struct {
+	fs     *fflib.FFLexer
+	stack  []byte
+	state  int
+	peek   fflib.FFTok
+	offset int
+}
+
+// NewTokenReader returns a TokenReader reading from r as it goes.
+func NewTokenReader(r io.Reader) *TokenReader {
+	return &TokenReader{fs: fflib.NewFFLexerReader(r), peek: fflib.FFTok_init}
+}
+
+// NewTokenReaderBytes returns a TokenReader reading from data.
+func NewTokenReaderBytes(data []byte) *TokenReader {
+	return &TokenReader{fs: fflib.NewFFLexer(data), peek: fflib.FFTok_init}
+}
+
+// Reset makes the TokenReader read from data, reusing its lexer.
+func (t *TokenReader) Reset(data []byte) {
+	t.fs.Reset(data)
+	t.reset()
+}
+
+// ResetReader makes the TokenReader read from r, reusing its lexer.
+func (t *TokenReader) ResetReader(r io.Reader) {
+	t.fs.ResetReader(r)
+	t.reset()
+}
+
+func (t *TokenReader) reset() {
+	t.stack = t.stack[:0]
+	t.state = tokenTopValue
+	t.peek = fflib.FFTok_init
+	t.offset = 0
+}
+
+// UseNumber causes Decode to unmarshal a number into an interface{} as a
+// json.Number instead of as a float64, when it falls back to encoding/json.
+func (t *TokenReader) UseNumber() {
+	t.fs.UseNumber = true
+}
+
+// InputOffset returns the input offset just after the last token returned.
+func (t *TokenReader) InputOffset() int {
+	return t.offset
+}
+
+// More reports whether there is another element in the current array or
+// object, or another top-level value.
+func (t *TokenReader) More() bool {
+	tok := t.scan()
+	t.peek = tok
+	return tok != fflib.FFTok_right_bracket &&
+		tok != fflib.FFTok_right_brace &&
+		tok != fflib.FFTok_eof &&
+		tok != fflib.FFTok_error
+}
+
+func (t *TokenReader) scan() fflib.FFTok {
+	if t.peek != fflib.FFTok_init {
+		tok := t.peek
+		t.peek = fflib.FFTok_init
+		return tok
+	}
+	for {
+		tok := t.fs.Scan()
+		if tok != fflib.FFTok_comment {
+			return tok
+		}
+	}
+}
+
+// Token returns the next token. At the end of the input it returns io.EOF,
+// or io.ErrUnexpectedEOF inside an array or object.
+func (t *TokenReader) Token() (Token, error) {
+	for {
+		tok := t.scan()
+		switch tok {
+		case fflib.FFTok_eof:
+			if len(t.stack) > 0 {
+				return Token{}, io.ErrUnexpectedEOF
+			}
+			return Token{}, io.EOF
+
+		case fflib.FFTok_error:
+			if t.fs.BigError == io.EOF {
+				// The input ended inside a token.
+				return Token{}, io.ErrUnexpectedEOF
+			}
+			if t.fs.BigError != nil {
+				return Token{}, t.fs.WrapErr(t.fs.BigError)
+			}
+			return Token{}, t.fs.WrapErr(t.fs.Error.ToError())
+
+		case fflib.FFTok_comma:
+			switch t.state {
+			case tokenArrayValue:
+				t.state = tokenArrayComma
+				continue
+			case tokenObjectValue:
+				t.state = tokenObjectComma
+				continue
+			}
+
+		case fflib.FFTok_colon:
+			if t.state == tokenObjectKey {
+				t.state = tokenObjectColon
+				continue
+			}
+
+		case fflib.FFTok_left_bracket:
+			if t.wantValue() {
+				t.stack = append(t.stack, '{')
+				t.state = tokenObjectStart
+				return t.token(TokenObjectStart)
+			}
+
+		case fflib.FFTok_right_bracket:
+			if t.state == tokenObjectStart || t.state == tokenObjectValue {
+				t.pop()
+				return t.token(TokenObjectEnd)
+			}
+
+		case fflib.FFTok_left_brace:
+			if t.wantValue() {
+				t.stack = append(t.stack, '[')
+				t.state = tokenArrayStart
+				return t.token(TokenArrayStart)
+			}
+
+		case fflib.FFTok_right_brace:
+			if t.state == tokenArrayStart || t.state == tokenArrayValue {
+				t.pop()
+				return t.token(TokenArrayEnd)
+			}
+
+		case fflib.FFTok_string:
+			if t.state == tokenObjectStart || t.state == tokenObjectComma {
+				t.state = tokenObjectKey
+				return t.token(TokenKey)
+			}
+			if t.wantValue() {
+				t.afterValue()
+				return t.token(TokenString)
+			}
+
+		case fflib.FFTok_integer, fflib.FFTok_double:
+			if t.wantValue() {
+				t.afterValue()
+				return t.token(TokenNumber)
+			}
+
+		case fflib.FFTok_bool:
+			if t.wantValue() {
+				t.afterValue()
+				return t.token(TokenBool)
+			}
+
+		case fflib.FFTok_null:
+			if t.wantValue() {
+				t.afterValue()
+				return t.token(TokenNull)
+			}
+		}
+
+		return Token{}, t.fs.WrapErr(fmt.Errorf("ffjson: unexpected token %v, expected %s", tok, t.expected()))
+	}
+}
+
+// Skip skips the next value, including all of a nested array or object,
+// without tokenizing its contents. If the TokenReader is before a key,
+// the key and its value are skipped.
+func (t *TokenReader) Skip() error {
+	tk, err := t.Token()
+	if err != nil {
+		return err
+	}
+	if tk.Kind == TokenKey {
+		tk, err = t.Token()
+		if err != nil {
+			return err
+		}
+	}
+
+	switch tk.Kind {
+	case TokenObjectStart:
+		err = t.fs.SkipField(fflib.FFTok_left_bracket)
+	case TokenArrayStart:
+		err = t.fs.SkipField(fflib.FFTok_left_brace)
+	case TokenObjectEnd, TokenArrayEnd:
+		return t.fs.WrapErr(fmt.Errorf("ffjson: cannot skip %v", tk.Kind))
+	default:
+		return nil
+	}
+	if err != nil {
+		return t.fs.WrapErr(err)
+	}
+	t.pop()
+	t.offset = t.fs.InputOffset()
+	return nil
+}
+
+// Decode decodes the next value into v, using ffjson generated code if
+// available and encoding/json otherwise.
+func (t *TokenReader) Decode(v interface{}) error {
+	tk, err := t.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tk.Kind {
+	case TokenObjectStart:
+		if f, ok := v.(unmarshalFaster); ok {
+			err = f.UnmarshalJSONFFLexer(t.fs, fflib.FFParse_want_key)
+			if err != nil {
+				return err
+			}
+			t.pop()
+			t.offset = t.fs.InputOffset()
+			return nil
+		}
+	case TokenKey, TokenObjectEnd, TokenArrayEnd:
+		return t.fs.WrapErr(fmt.Errorf("ffjson: cannot decode %v", tk.Kind))
+	}
+
+	// CaptureField picks up from the token that was just scanned.
+	raw, err := t.fs.CaptureField(t.fs.Token)
+	if err != nil {
+		return t.fs.WrapErr(err)
+	}
+	if tk.Kind == TokenObjectStart || tk.Kind == TokenArrayStart {
+		t.pop()
+		t.offset = t.fs.InputOffset()
+	}
+	return t.fs.UnmarshalFallback(raw, v)
+}
+
+func (t *TokenReader) token(kind TokenKind) (Token, error) {
+	t.offset = t.fs.InputOffset()
+	return Token{Kind: kind, b: t.fs.Output.Bytes()}, nil
+}
+
+func (t *TokenReader) wantValue() bool {
+	switch t.state {
+	case tokenTopValue, tokenArrayStart, tokenArrayComma, tokenObjectColon:
+		return true
+	}
+	return false
+}
+
+func (t *TokenReader) afterValue() {
+	switch {
+	case len(t.stack) == 0:
+		t.state = tokenTopValue
+	case t.stack[len(t.stack)-1] == '[':
+		t.state = tokenArrayValue
+	default:
+		t.state = tokenObjectValue
+	}
+}
+
+func (t *TokenReader) pop() {
+	t.stack = t.stack[:len(t.stack)-1]
+	t.afterValue()
+}
+
+func (t *TokenReader) expected() string {
+	switch t.state {
+	case tokenArrayStart:
+		return "value or ']'"
+	case tokenArrayValue:
+		return "',' or ']'"
+	case tokenObjectStart:
+		return "key or '}'"
+	case tokenObjectKey:
+		return "':'"
+	case tokenObjectValue:
+		return "',' or '}'"
+	case tokenObjectComma:
+		return "key"
+	}
+	return "value"
+}
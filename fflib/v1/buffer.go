@@ -80,6 +80,8 @@ type Buffer struct {
 	runeBytes        [utf8.UTFMax]byte // avoid allocation of slice on each WriteByte or Rune
 	encoder          *json.Encoder
 	skipTrailingByte bool
+	escaping         Escaping
+	escapeErr        error
 }
 
 // ErrTooLarge is passed to panic if memory cannot be allocated to store data in a buffer.
@@ -277,9 +279,21 @@ func (b *Buffer) Encode(v interface{}) error {
 	if b.encoder == nil {
 		b.encoder = json.NewEncoder(b)
 	}
+	mode := b.escaping.Mode()
+	b.encoder.SetEscapeHTML(mode != EscapeMinimal)
+	start := b.Len()
 	b.skipTrailingByte = true
 	err := b.encoder.Encode(v)
 	b.skipTrailingByte = false
+	if err == nil && mode == EscapeASCII {
+		// encoding/json has no ASCII-only mode, escape what it wrote.
+		out := b.Bytes()[start:]
+		if bytes.IndexFunc(out, func(r rune) bool { return r >= utf8.RuneSelf }) >= 0 {
+			out = append([]byte(nil), out...)
+			b.Truncate(start)
+			escapeASCII(b, out)
+		}
+	}
 	return err
 }
 
@@ -0,0 +1,102 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// AppendBuffer is an EncodingBuffer that appends to a caller-owned slice,
+// like the strconv Append functions. It is used by generated AppendJSON
+// methods:
+//
+//	buf := fflib.AppendBuffer(dst)
+//	err := j.MarshalJSONBuf(&buf)
+//	dst = buf
+//
+// Unlike with Buffer, the memory is never taken from or handed to the pool.
+type AppendBuffer []byte
+
+func (b *AppendBuffer) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
+func (b *AppendBuffer) WriteString(s string) (int, error) {
+	*b = append(*b, s...)
+	return len(s), nil
+}
+
+func (b *AppendBuffer) WriteByte(c byte) error {
+	*b = append(*b, c)
+	return nil
+}
+
+// Encode appends v encoded with encoding/json.
+func (b *AppendBuffer) Encode(v interface{}) error {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	*b = append(*b, out...)
+	return nil
+}
+
+func (b *AppendBuffer) Rewind(n int) error {
+	*b = (*b)[:len(*b)-n]
+	return nil
+}
+
+// Grow makes sure that n more bytes can be appended without another
+// allocation.
+func (b *AppendBuffer) Grow(n int) {
+	if n < 0 {
+		panic("fflib.v1.AppendBuffer.Grow: negative count")
+	}
+	if cap(*b)-len(*b) < n {
+		grown := make([]byte, len(*b), 2*cap(*b)+n)
+		copy(grown, *b)
+		*b = grown
+	}
+}
+
+// Truncate discards all but the first n bytes of the slice, including
+// any bytes it held before it was handed to AppendBuffer.
+func (b *AppendBuffer) Truncate(n int) {
+	*b = (*b)[:n]
+}
+
+// Reset empties the slice, see Truncate.
+func (b *AppendBuffer) Reset() {
+	*b = (*b)[:0]
+}
+
+// WriteTo writes the whole slice to w and empties it.
+func (b *AppendBuffer) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(*b)
+	if err == nil && n != len(*b) {
+		err = io.ErrShortWrite
+	}
+	if n == len(*b) {
+		*b = (*b)[:0]
+	} else {
+		*b = (*b)[n:]
+	}
+	return int64(n), err
+}
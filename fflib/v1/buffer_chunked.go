@@ -0,0 +1,171 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	defaultChunkSize = 32 * 1024
+
+	// Bytes that ChunkedBuffer and IndentBuffer hold back, so they can
+	// still be rewound. Generated code only ever rewinds a single byte.
+	rewindHoldBack = 8
+)
+
+// ErrRewindFlushed is returned by ChunkedBuffer.Rewind and IndentBuffer.Rewind
+// when the bytes to rewind have already been written out.
+var ErrRewindFlushed = errors.New("fflib.v1: cannot rewind flushed bytes")
+
+// ChunkedBuffer is an EncodingBuffer that writes its content to an
+// io.Writer whenever it grows past the chunk size, so encoding a very large
+// value only needs memory for about one chunk of it instead of all of it.
+// The last few bytes are held back until Flush, so Rewind works for
+// the small amounts generated code rewinds.
+//
+// Errors from the writer are sticky: once a write failed, further output is
+// discarded, and Flush returns the error.
+type ChunkedBuffer struct {
+	buf  Buffer
+	w    io.Writer
+	size int
+	err  error
+}
+
+// NewChunkedBuffer returns a ChunkedBuffer writing to w in chunks of about
+// size bytes. If size is <= 0 a default of 32 KiB is used.
+func NewChunkedBuffer(w io.Writer, size int) *ChunkedBuffer {
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	if size < 2*rewindHoldBack {
+		size = 2 * rewindHoldBack
+	}
+	return &ChunkedBuffer{w: w, size: size}
+}
+
+func (b *ChunkedBuffer) check() {
+	if b.buf.Len() >= b.size {
+		b.flush(rewindHoldBack)
+	}
+}
+
+// flush writes out all but the last keep bytes.
+func (b *ChunkedBuffer) flush(keep int) {
+	n := b.buf.Len() - keep
+	if n <= 0 {
+		return
+	}
+	if b.err == nil {
+		var m int
+		m, b.err = b.w.Write(b.buf.Bytes()[:n])
+		if b.err == nil && m != n {
+			b.err = io.ErrShortWrite
+		}
+	}
+	b.buf.Next(n)
+}
+
+// Flush writes out everything that is buffered, and returns the first error
+// the writer returned, if any.
+func (b *ChunkedBuffer) Flush() error {
+	b.flush(0)
+	return b.err
+}
+
+// Write appends p, flushing a chunk if the buffer has grown past the chunk
+// size. It returns the sticky write error, if any.
+func (b *ChunkedBuffer) Write(p []byte) (int, error) {
+	n, _ := b.buf.Write(p)
+	b.check()
+	return n, b.err
+}
+
+// WriteString appends s, like Write.
+func (b *ChunkedBuffer) WriteString(s string) (int, error) {
+	n, _ := b.buf.WriteString(s)
+	b.check()
+	return n, b.err
+}
+
+// WriteByte appends c, like Write.
+func (b *ChunkedBuffer) WriteByte(c byte) error {
+	b.buf.WriteByte(c)
+	b.check()
+	return b.err
+}
+
+// Encode appends v encoded with encoding/json, like Write.
+func (b *ChunkedBuffer) Encode(v interface{}) error {
+	err := b.buf.Encode(v)
+	if err != nil {
+		return err
+	}
+	b.check()
+	return b.err
+}
+
+// Rewind removes the last n bytes. They must not have been flushed yet,
+// which is guaranteed for n up to 8.
+func (b *ChunkedBuffer) Rewind(n int) error {
+	if n > b.buf.Len() {
+		return ErrRewindFlushed
+	}
+	return b.buf.Rewind(n)
+}
+
+// Grow grows the capacity of the buffer, see Buffer.Grow.
+func (b *ChunkedBuffer) Grow(n int) {
+	b.buf.Grow(n)
+}
+
+// Truncate discards all but the first n bytes that have not been flushed.
+func (b *ChunkedBuffer) Truncate(n int) {
+	b.buf.Truncate(n)
+}
+
+// Reset discards the bytes that have not been flushed, and the sticky error,
+// so the ChunkedBuffer can be reused for another value.
+func (b *ChunkedBuffer) Reset() {
+	b.buf.Reset()
+	b.err = nil
+}
+
+func (b *ChunkedBuffer) Escaping() Escaping {
+	return b.buf.Escaping()
+}
+
+func (b *ChunkedBuffer) SetEscaping(e Escaping) {
+	b.buf.SetEscaping(e)
+}
+
+func (b *ChunkedBuffer) EscapeError() error {
+	return b.buf.EscapeError()
+}
+
+func (b *ChunkedBuffer) setEscapeError(err error) {
+	b.buf.setEscapeError(err)
+}
+
+// WriteTo writes the bytes that have not been flushed to w instead, and
+// removes them from the buffer.
+func (b *ChunkedBuffer) WriteTo(w io.Writer) (int64, error) {
+	return b.buf.WriteTo(w)
+}
@@ -0,0 +1,135 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+type chunkRecorder struct {
+	bytes.Buffer
+	writes  int
+	maxSize int
+}
+
+func (w *chunkRecorder) Write(p []byte) (int, error) {
+	w.writes++
+	if len(p) > w.maxSize {
+		w.maxSize = len(p)
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestChunkedBuffer(t *testing.T) {
+	var expect Buffer
+	var out chunkRecorder
+	var cb EncodingBuffer = NewChunkedBuffer(&out, 64)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		for _, b := range []EncodingBuffer{&expect, cb} {
+			switch i % 4 {
+			case 0:
+				b.WriteString(strings.Repeat("s", i%50))
+			case 1:
+				b.Write([]byte{'[', '1', ','})
+			case 2:
+				b.WriteByte(',')
+			case 3:
+				b.Rewind(1)
+			}
+		}
+		if r.Intn(100) == 0 {
+			expect.Encode(map[string]int{"a": i})
+			cb.Encode(map[string]int{"a": i})
+		}
+	}
+	if err := cb.(*ChunkedBuffer).Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(expect.Bytes(), out.Bytes()) {
+		t.Fatalf("chunked output differs from Buffer output")
+	}
+	if out.writes < 100 {
+		t.Fatalf("expected many small writes, got %d", out.writes)
+	}
+	if out.maxSize > 64+64 {
+		t.Fatalf("expected writes of about 64 bytes, got %d", out.maxSize)
+	}
+}
+
+func TestChunkedBufferRewind(t *testing.T) {
+	var out bytes.Buffer
+	cb := NewChunkedBuffer(&out, 16)
+	cb.WriteString(strings.Repeat("x", 100))
+
+	if err := cb.Rewind(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := cb.Rewind(50); err != ErrRewindFlushed {
+		t.Fatalf("expected ErrRewindFlushed, got %v", err)
+	}
+	cb.Flush()
+	if out.String() != strings.Repeat("x", 99) {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+type failingWriter struct {
+	n int
+}
+
+var errWriteFailed = errors.New("write failed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.n++
+	if w.n > 1 {
+		return 0, errWriteFailed
+	}
+	return len(p), nil
+}
+
+func TestChunkedBufferError(t *testing.T) {
+	w := &failingWriter{}
+	cb := NewChunkedBuffer(w, 16)
+	for i := 0; i < 100; i++ {
+		cb.WriteString("0123456789")
+	}
+	if _, err := cb.WriteString("x"); err != errWriteFailed {
+		t.Fatalf("expected sticky error, got %v", err)
+	}
+	if w.n != 2 {
+		t.Fatalf("expected no writes after the error, got %d", w.n)
+	}
+	if cb.buf.Len() > 32 {
+		t.Fatalf("expected output to be discarded after the error, %d bytes are buffered", cb.buf.Len())
+	}
+	if err := cb.Flush(); err != errWriteFailed {
+		t.Fatalf("expected error from Flush, got %v", err)
+	}
+
+	cb.Reset()
+	if err := cb.Flush(); err != nil {
+		t.Fatalf("expected Reset to clear the error, got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+/* Portions of this file are on Go stdlib's encoding/json/indent.go */
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"io"
+)
+
+// IndentBuffer is an EncodingBuffer that indents the JSON written to it
+// on the fly, and writes the result to another buffer. The output is the
+// same as json.Indent would produce for the compacted input, so running
+// generated MarshalJSONBuf through it gives the output of json.MarshalIndent.
+// Whitespace outside of strings is dropped from the input.
+//
+// The last few bytes are held back until Flush, so Rewind works for
+// the small amounts generated code rewinds.
+type IndentBuffer struct {
+	raw    Buffer
+	out    JsonStringWriter
+	prefix string
+	indent string
+
+	depth      int
+	needIndent bool
+	inString   bool
+	escaped    bool
+}
+
+// NewIndentBuffer returns an IndentBuffer writing to out. Each element in
+// a JSON object or array begins on a new line beginning with prefix,
+// followed by one or more copies of indent according to the nesting depth.
+func NewIndentBuffer(out JsonStringWriter, prefix, indent string) *IndentBuffer {
+	return &IndentBuffer{out: out, prefix: prefix, indent: indent}
+}
+
+func (b *IndentBuffer) check() {
+	if b.raw.Len() > 2*rewindHoldBack {
+		b.process(b.raw.Len() - rewindHoldBack)
+	}
+}
+
+// Flush indents everything that is buffered to the output.
+func (b *IndentBuffer) Flush() {
+	b.process(b.raw.Len())
+}
+
+// process indents the first n buffered bytes to the output.
+func (b *IndentBuffer) process(n int) {
+	s := b.raw.Next(n)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if b.inString {
+			// Copy runs of plain string bytes in one go.
+			j := i
+			for j < len(s) && !b.escaped && s[j] != '"' && s[j] != '\\' {
+				j++
+			}
+			if j > i {
+				b.out.Write(s[i:j])
+				if j == len(s) {
+					return
+				}
+				i = j
+				c = s[i]
+			}
+
+			b.out.WriteByte(c)
+			if b.escaped {
+				b.escaped = false
+			} else if c == '\\' {
+				b.escaped = true
+			} else if c == '"' {
+				b.inString = false
+			}
+			continue
+		}
+
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			continue
+		}
+
+		if b.needIndent && c != '}' && c != ']' {
+			b.needIndent = false
+			b.depth++
+			b.newline()
+		}
+
+		switch c {
+		case '"':
+			b.inString = true
+			b.out.WriteByte(c)
+		case '{', '[':
+			// Delay the newline, empty objects and arrays stay on one line.
+			b.needIndent = true
+			b.out.WriteByte(c)
+		case ',':
+			b.out.WriteByte(c)
+			b.newline()
+		case ':':
+			b.out.WriteByte(c)
+			b.out.WriteByte(' ')
+		case '}', ']':
+			if b.needIndent {
+				b.needIndent = false
+			} else {
+				b.depth--
+				b.newline()
+			}
+			b.out.WriteByte(c)
+		default:
+			b.out.WriteByte(c)
+		}
+	}
+}
+
+func (b *IndentBuffer) newline() {
+	b.out.WriteByte('\n')
+	b.out.WriteString(b.prefix)
+	for i := 0; i < b.depth; i++ {
+		b.out.WriteString(b.indent)
+	}
+}
+
+func (b *IndentBuffer) Write(p []byte) (int, error) {
+	n, _ := b.raw.Write(p)
+	b.check()
+	return n, nil
+}
+
+func (b *IndentBuffer) WriteString(s string) (int, error) {
+	n, _ := b.raw.WriteString(s)
+	b.check()
+	return n, nil
+}
+
+func (b *IndentBuffer) WriteByte(c byte) error {
+	b.raw.WriteByte(c)
+	b.check()
+	return nil
+}
+
+// Encode appends v encoded with encoding/json, to be indented like the rest.
+func (b *IndentBuffer) Encode(v interface{}) error {
+	err := b.raw.Encode(v)
+	if err != nil {
+		return err
+	}
+	b.check()
+	return nil
+}
+
+// Rewind removes the last n bytes written. They must not have been indented
+// to the output yet, which is guaranteed for n up to 8.
+func (b *IndentBuffer) Rewind(n int) error {
+	if n > b.raw.Len() {
+		return ErrRewindFlushed
+	}
+	return b.raw.Rewind(n)
+}
+
+func (b *IndentBuffer) Grow(n int) {
+	b.raw.Grow(n)
+}
+
+// Truncate discards all but the first n bytes that have not been indented
+// to the output yet.
+func (b *IndentBuffer) Truncate(n int) {
+	b.raw.Truncate(n)
+}
+
+// Reset discards the bytes that have not been indented to the output yet,
+// and the indentation state, so the IndentBuffer can be reused for another
+// value.
+func (b *IndentBuffer) Reset() {
+	b.raw.Reset()
+	b.depth = 0
+	b.needIndent = false
+	b.inString = false
+	b.escaped = false
+}
+
+func (b *IndentBuffer) Escaping() Escaping {
+	return b.raw.Escaping()
+}
+
+func (b *IndentBuffer) SetEscaping(e Escaping) {
+	b.raw.SetEscaping(e)
+}
+
+func (b *IndentBuffer) EscapeError() error {
+	return b.raw.EscapeError()
+}
+
+func (b *IndentBuffer) setEscapeError(err error) {
+	b.raw.setEscapeError(err)
+}
+
+// WriteTo indents the bytes that have not been indented yet to w instead of
+// the output.
+func (b *IndentBuffer) WriteTo(w io.Writer) (int64, error) {
+	var tmp Buffer
+	out := b.out
+	b.out = &tmp
+	b.Flush()
+	b.out = out
+	return tmp.WriteTo(w)
+}
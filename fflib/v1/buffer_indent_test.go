@@ -0,0 +1,74 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+var indentInputs = []string{
+	`{}`,
+	`[]`,
+	`{"a":[1,2,{}],"b":{"c":[]},"d":"x"}`,
+	`[{"k\"{[":"v\\"},[[["deep"]]],null,true,-1.5e3]`,
+	`{ "spaced" : [ 1 , 2 ] ,
+	"nl":"\n"}`,
+	`"top"`,
+}
+
+func TestIndentBuffer(t *testing.T) {
+	for _, input := range indentInputs {
+		var expect bytes.Buffer
+		if err := json.Indent(&expect, []byte(input), "> ", "\t"); err != nil {
+			t.Fatal(err)
+		}
+
+		// Written a byte at the time, with a comma rewound after every
+		// byte, like generated code does after the last element.
+		var out Buffer
+		ib := NewIndentBuffer(&out, "> ", "\t")
+		for i := 0; i < len(input); i++ {
+			ib.WriteByte(input[i])
+			ib.WriteByte(',')
+			if err := ib.Rewind(1); err != nil {
+				t.Fatal(err)
+			}
+		}
+		ib.Flush()
+
+		if out.String() != expect.String() {
+			t.Fatalf("IndentBuffer(%s): got\n%s\nexpected\n%s", input, out.String(), expect.String())
+		}
+	}
+}
+
+func TestIndentBufferReset(t *testing.T) {
+	var out Buffer
+	ib := NewIndentBuffer(&out, "", " ")
+	ib.WriteString(`{"a":["unfinished`)
+	ib.Reset()
+	out.Reset()
+
+	ib.WriteString(`[1]`)
+	ib.Flush()
+	if out.String() != "[\n 1\n]" {
+		t.Fatalf("unexpected output after Reset: %q", out.String())
+	}
+}
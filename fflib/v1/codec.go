@@ -0,0 +1,75 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Codec encodes and decodes the values of one type, when the type is only
+// known at runtime: for values in interface fields of generated code, and
+// in the ffjson Marshal and Unmarshal functions, Encoder and Decoder. v is
+// a T in EncodeValue, and a *T in DecodeValue, which is called like the
+// Decode method of field codecs.
+type Codec interface {
+	EncodeValue(buf EncodingBuffer, v interface{}) error
+	DecodeValue(fs *FFLexer, tok FFTok, v interface{}) error
+}
+
+var (
+	codecsMu sync.Mutex
+	codecs   atomic.Value // map[reflect.Type]Codec, replaced on every change
+)
+
+// RegisterCodec registers c for the type of v, replacing any codec
+// registered for it before. It is meant to be called from init functions.
+func RegisterCodec(v interface{}, c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	old, _ := codecs.Load().(map[reflect.Type]Codec)
+	m := make(map[reflect.Type]Codec, len(old)+1)
+	for t, c := range old {
+		m[t] = c
+	}
+	m[reflect.TypeOf(v)] = c
+	codecs.Store(m)
+}
+
+// LookupCodec returns the codec registered for t, or nil.
+func LookupCodec(t reflect.Type) Codec {
+	m, _ := codecs.Load().(map[reflect.Type]Codec)
+	if len(m) == 0 {
+		return nil
+	}
+	return m[t]
+}
+
+// EncodeInterface writes v with the codec registered for its type, or
+// with buf.Encode if there is none. Generated code uses it for values of
+// interface fields.
+func EncodeInterface(buf EncodingBuffer, v interface{}) error {
+	if v != nil {
+		if c := LookupCodec(reflect.TypeOf(v)); c != nil {
+			return c.EncodeValue(buf, v)
+		}
+	}
+	return buf.Encode(v)
+}
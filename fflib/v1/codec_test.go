@@ -0,0 +1,73 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testCodecType struct {
+	A int
+}
+
+type testCodec struct{}
+
+func (testCodec) EncodeValue(buf EncodingBuffer, v interface{}) error {
+	buf.WriteString(`"codec"`)
+	return nil
+}
+
+func (testCodec) DecodeValue(fs *FFLexer, tok FFTok, v interface{}) error {
+	return errors.New("not implemented")
+}
+
+func TestCodecRegistry(t *testing.T) {
+	typ := reflect.TypeOf(testCodecType{})
+	if LookupCodec(typ) != nil {
+		t.Fatal("LookupCodec found a codec before RegisterCodec")
+	}
+
+	var buf Buffer
+	if err := EncodeInterface(&buf, testCodecType{A: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != `{"A":1}` {
+		t.Fatalf("EncodeInterface without codec: got %s", buf.String())
+	}
+
+	RegisterCodec(testCodecType{}, testCodec{})
+	if _, ok := LookupCodec(typ).(testCodec); !ok {
+		t.Fatal("LookupCodec did not find the registered codec")
+	}
+	if LookupCodec(reflect.PtrTo(typ)) != nil {
+		t.Fatal("LookupCodec found a codec for the pointer type")
+	}
+
+	buf.Reset()
+	if err := EncodeInterface(&buf, testCodecType{A: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := EncodeInterface(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != `"codec"null` {
+		t.Fatalf("EncodeInterface with codec: got %s", buf.String())
+	}
+}
@@ -0,0 +1,181 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"io"
+)
+
+// Where CompactValue is in the JSON grammar.
+const (
+	compactValue       = iota // a value
+	compactArrayStart         // after '[', a value or ']'
+	compactObjectStart        // after '{', a key or '}'
+	compactKey                // after ',' in an object, a key
+	compactColon              // after a key, ':'
+	compactAfterValue         // after a value, ',' or the end of the array or object
+)
+
+// CompactValue scans the next JSON value, including all of a nested array
+// or object, and checks that its tokens follow the JSON grammar, which Scan
+// alone does not. Comments are not allowed.
+//
+// If dst is not nil, the value is written to it without insignificant
+// whitespace, like json.Compact. Strings are copied as they are in the
+// input, escapes included.
+//
+// CompactValue stops right after the value, see ExpectEnd.
+func (ffl *FFLexer) CompactValue(dst JsonStringWriter) error {
+	var stackBuf [64]byte
+	stack := stackBuf[:0]
+	state := compactValue
+
+	for {
+		tok := ffl.Scan()
+
+		switch state {
+		case compactAfterValue:
+			top := stack[len(stack)-1]
+			c := byte(',')
+			switch {
+			case tok == FFTok_comma && top == '[':
+				state = compactValue
+			case tok == FFTok_comma:
+				state = compactKey
+			case tok == FFTok_right_brace && top == '[':
+				c = ']'
+				stack = stack[:len(stack)-1]
+			case tok == FFTok_right_bracket && top == '{':
+				c = '}'
+				stack = stack[:len(stack)-1]
+			default:
+				return ffl.compactError(tok)
+			}
+			if dst != nil {
+				dst.WriteByte(c)
+			}
+			if len(stack) == 0 {
+				return nil
+			}
+			continue
+
+		case compactColon:
+			if tok != FFTok_colon {
+				return ffl.compactError(tok)
+			}
+			if dst != nil {
+				dst.WriteByte(':')
+			}
+			state = compactValue
+			continue
+
+		case compactObjectStart, compactKey:
+			if tok == FFTok_string {
+				ffl.compactString(dst)
+				state = compactColon
+				continue
+			}
+			if tok != FFTok_right_bracket || state == compactKey {
+				return ffl.compactError(tok)
+			}
+			stack = stack[:len(stack)-1]
+			if dst != nil {
+				dst.WriteByte('}')
+			}
+			state = compactAfterValue
+			if len(stack) == 0 {
+				return nil
+			}
+			continue
+
+		case compactArrayStart:
+			if tok == FFTok_right_brace {
+				stack = stack[:len(stack)-1]
+				if dst != nil {
+					dst.WriteByte(']')
+				}
+				state = compactAfterValue
+				if len(stack) == 0 {
+					return nil
+				}
+				continue
+			}
+		}
+
+		// A value is expected.
+		switch tok {
+		case FFTok_left_bracket:
+			stack = append(stack, '{')
+			state = compactObjectStart
+			if dst != nil {
+				dst.WriteByte('{')
+			}
+			continue
+		case FFTok_left_brace:
+			stack = append(stack, '[')
+			state = compactArrayStart
+			if dst != nil {
+				dst.WriteByte('[')
+			}
+			continue
+		case FFTok_string:
+			ffl.compactString(dst)
+		case FFTok_integer, FFTok_double, FFTok_bool, FFTok_null:
+			if dst != nil {
+				dst.Write(ffl.Output.Bytes())
+			}
+		default:
+			return ffl.compactError(tok)
+		}
+		if len(stack) == 0 {
+			return nil
+		}
+		state = compactAfterValue
+	}
+}
+
+// compactString writes the string token that was just scanned to dst as it
+// is in the input, or escaped again if it is no longer in the window.
+func (ffl *FFLexer) compactString(dst JsonStringWriter) {
+	if dst == nil {
+		return
+	}
+	if ffl.tokenStart >= ffl.reader.off {
+		dst.Write(ffl.reader.Slice(ffl.tokenStart, ffl.reader.Pos()))
+		return
+	}
+	WriteJson(dst, ffl.Output.Bytes())
+}
+
+func (ffl *FFLexer) compactError(tok FFTok) error {
+	switch tok {
+	case FFTok_eof:
+		return ffl.WrapErr(io.ErrUnexpectedEOF)
+	case FFTok_error:
+		if ffl.BigError == io.EOF {
+			// The input ended inside a token.
+			return ffl.WrapErr(io.ErrUnexpectedEOF)
+		}
+		if ffl.BigError != nil {
+			return ffl.WrapErr(ffl.BigError)
+		}
+		return ffl.WrapErr(ffl.Error.ToError())
+	}
+	return ffl.WrapErr(fmt.Errorf("ffjson: unexpected %v", tok))
+}
@@ -0,0 +1,58 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+// FieldSet selects the fields written by generated MarshalJSONFields
+// methods. Bit i is set when the i-th field of the structure is included,
+// generated JSONFieldSet methods build it from JSON names. The zero value
+// is the empty set.
+type FieldSet []uint64
+
+// Has returns whether field i is in the set.
+func (s FieldSet) Has(i int) bool {
+	w := i >> 6
+	return w < len(s) && s[w]&(1<<uint(i&63)) != 0
+}
+
+// Add adds field i to the set.
+func (s *FieldSet) Add(i int) {
+	w := i >> 6
+	for len(*s) <= w {
+		*s = append(*s, 0)
+	}
+	(*s)[w] |= 1 << uint(i&63)
+}
+
+// Remove removes field i from the set.
+func (s FieldSet) Remove(i int) {
+	w := i >> 6
+	if w < len(s) {
+		s[w] &^= 1 << uint(i&63)
+	}
+}
+
+// Len returns the number of fields in the set.
+func (s FieldSet) Len() int {
+	n := 0
+	for _, w := range s {
+		for ; w != 0; w &= w - 1 {
+			n++
+		}
+	}
+	return n
+}
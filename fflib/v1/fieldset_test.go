@@ -0,0 +1,53 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestFieldSet(t *testing.T) {
+	var s FieldSet
+	if s.Has(0) || s.Len() != 0 {
+		t.Fatal("zero FieldSet is not empty")
+	}
+
+	fields := []int{0, 5, 63, 64, 130}
+	for _, i := range fields {
+		s.Add(i)
+	}
+	s.Add(5)
+	if s.Len() != len(fields) {
+		t.Fatalf("Len: got %d, expected %d", s.Len(), len(fields))
+	}
+	for i := 0; i < 200; i++ {
+		expect := false
+		for _, f := range fields {
+			expect = expect || f == i
+		}
+		if s.Has(i) != expect {
+			t.Fatalf("Has(%d): got %v", i, s.Has(i))
+		}
+	}
+
+	s.Remove(64)
+	s.Remove(500)
+	if s.Has(64) || !s.Has(63) || s.Len() != len(fields)-1 {
+		t.Fatal("Remove(64) removed the wrong fields")
+	}
+}
@@ -92,31 +92,15 @@ func AppendFloat(dst EncodingBuffer, val float64, fmt byte, prec, bitSize int) {
 		return
 	}
 
+	// Negative precision means "only as much as needed to be exact."
+	if prec < 0 {
+		WriteFloat(dst, val, fmt, bitSize)
+		return
+	}
+
 	var digs decimalSlice
 	ok := false
-	// Negative precision means "only as much as needed to be exact."
-	shortest := prec < 0
-	if shortest {
-		// Try Grisu3 algorithm.
-		f := new(extFloat)
-		lower, upper := f.AssignComputeBounds(mant, exp, neg, flt)
-		var buf [32]byte
-		digs.d = buf[:]
-		ok = f.ShortestDecimal(&digs, &lower, &upper)
-		if !ok {
-			bigFtoa(dst, prec, fmt, neg, mant, exp, flt)
-			return
-		}
-		// Precision for shortest representation mode.
-		switch fmt {
-		case 'e', 'E':
-			prec = max(digs.nd-1, 0)
-		case 'f':
-			prec = max(digs.nd-digs.dp, 0)
-		case 'g', 'G':
-			prec = digs.nd
-		}
-	} else if fmt != 'f' {
+	if fmt != 'f' {
 		// Fixed number of digits.
 		digits := prec
 		switch fmt {
@@ -140,7 +124,7 @@ func AppendFloat(dst EncodingBuffer, val float64, fmt byte, prec, bitSize int) {
 		bigFtoa(dst, prec, fmt, neg, mant, exp, flt)
 		return
 	}
-	formatDigits(dst, shortest, neg, digs, prec, fmt)
+	formatDigits(dst, false, neg, digs, prec, fmt)
 	return
 }
 
@@ -178,7 +162,7 @@ func bigFtoa(dst EncodingBuffer, prec int, fmt byte, neg bool, mant uint64, exp
 		}
 		digs = decimalSlice{d: d.d[:], nd: d.nd, dp: d.dp}
 	}
-	formatDigits(dst, shortest, neg, digs, prec, fmt)
+	formatDigits(dst, false, neg, digs, prec, fmt)
 	return
 }
 
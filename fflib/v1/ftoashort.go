@@ -0,0 +1,325 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+/* Portions of this file are on Go stdlib's internal/strconv/uscale.go and ftoa.go */
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package v1
+
+// Shortest float formatting by fast unrounded scaling, a table driven
+// algorithm in the same family as Ryu: a single 128-bit multiply against
+// pow10Tab gives the decimal interval that rounds back to the float, and
+// the shortest digit string in that interval is picked directly, with no
+// bignum fallback.
+// See "Floating-Point Printing and Parsing Can Be Simple And Fast",
+// https://research.swtch.com/fp
+
+import (
+	"math"
+	"math/bits"
+)
+
+const (
+	float32MinExp = -189
+	float64MinExp = -1085
+)
+
+// WriteFloat writes the shortest string form of f that parses back to the
+// same float32 or float64, exactly as strconv.AppendFloat(dst, f, fmt, -1, bitSize)
+// would. The number is formatted into a stack buffer and written with
+// one call to buf.Write.
+func WriteFloat(buf EncodingBuffer, f float64, fmt byte, bitSize int) {
+	var scratch [64]byte
+	buf.Write(AppendFloatShortest(scratch[:0], f, fmt, bitSize))
+}
+
+// AppendFloatShortest appends the shortest string form of f that parses
+// back to the same float32 or float64 to dst, in the 'e', 'E', 'f', 'g'
+// or 'G' format, and returns the extended buffer.
+func AppendFloatShortest(dst []byte, f float64, fmt byte, bitSize int) []byte {
+	var b uint64
+	var flt *floatInfo
+	minExp := float64MinExp
+	switch bitSize {
+	case 32:
+		b = uint64(math.Float32bits(float32(f)))
+		flt = &float32info
+		minExp = float32MinExp
+	case 64:
+		b = math.Float64bits(f)
+		flt = &float64info
+	default:
+		panic("ffjson: illegal AppendFloatShortest bitSize")
+	}
+
+	neg := b>>(flt.expbits+flt.mantbits) != 0
+	exp := int(b>>flt.mantbits) & (1<<flt.expbits - 1)
+	mant := b & (uint64(1)<<flt.mantbits - 1)
+	if exp == 1<<flt.expbits-1 {
+		if mant != 0 {
+			return append(dst, "NaN"...)
+		}
+		if neg {
+			return append(dst, "-Inf"...)
+		}
+		return append(dst, "+Inf"...)
+	}
+	if exp == 0 {
+		exp++
+	} else {
+		mant |= uint64(1) << flt.mantbits
+	}
+	exp += flt.bias
+
+	// Pick off zero.
+	if mant == 0 {
+		return fmtShortest(dst, neg, nil, 0, 0, fmt)
+	}
+
+	var digs [32]byte
+	s := 64 - bits.Len64(mant)
+	d, p := shortFloat(mant<<s, exp-s-int(flt.mantbits), int(flt.mantbits), minExp)
+	dp, nd := setDigits(digs[:], d, p, numDigits(d))
+	return fmtShortest(dst, neg, digs[:], dp, nd, fmt)
+}
+
+// fmtShortest formats the nd digits in s, with the decimal point at dp.
+func fmtShortest(dst []byte, neg bool, s []byte, dp, nd int, fmt byte) []byte {
+	var prec int
+	switch fmt {
+	case 'e', 'E':
+		prec = max(nd-1, 0)
+	case 'f':
+		prec = max(nd-dp, 0)
+	case 'g', 'G':
+		// %e is used if the exponent from the conversion
+		// is less than -4 or greater than or equal to the precision.
+		// if precision was the shortest possible, use precision 6 for this decision.
+		if exp := dp - 1; exp < -4 || exp >= 6 {
+			prec = nd - 1
+			fmt = fmt + 'e' - 'g'
+		} else {
+			prec = max(nd-dp, 0)
+			fmt = 'f'
+		}
+	}
+
+	switch fmt {
+	case 'e', 'E': // %e: -d.ddddde±dd
+		if neg {
+			dst = append(dst, '-')
+		}
+
+		// first digit
+		ch := byte('0')
+		if nd != 0 {
+			ch = s[0]
+		}
+		dst = append(dst, ch)
+
+		// .moredigits
+		if prec > 0 {
+			dst = append(dst, '.')
+			dst = append(dst, s[1:nd]...)
+		}
+
+		// e±
+		dst = append(dst, fmt)
+		exp := dp - 1
+		if nd == 0 { // special case: 0 has exponent 0
+			exp = 0
+		}
+		if exp < 0 {
+			ch = '-'
+			exp = -exp
+		} else {
+			ch = '+'
+		}
+		dst = append(dst, ch)
+
+		// dd or ddd
+		switch {
+		case exp < 10:
+			dst = append(dst, '0', byte(exp)+'0')
+		case exp < 100:
+			dst = append(dst, byte(exp/10)+'0', byte(exp%10)+'0')
+		default:
+			dst = append(dst, byte(exp/100)+'0', byte(exp/10)%10+'0', byte(exp%10)+'0')
+		}
+		return dst
+
+	case 'f': // %f: -ddddddd.ddddd
+		if neg {
+			dst = append(dst, '-')
+		}
+
+		// integer, padded with zeros as needed.
+		if dp > 0 {
+			m := min(nd, dp)
+			dst = append(dst, s[:m]...)
+			for i := m; i < dp; i++ {
+				dst = append(dst, '0')
+			}
+		} else {
+			dst = append(dst, '0')
+		}
+
+		// fraction
+		if prec > 0 {
+			dst = append(dst, '.')
+			for i := dp; i < 0; i++ {
+				dst = append(dst, '0')
+			}
+			dst = append(dst, s[max(dp, 0):nd]...)
+		}
+		return dst
+	}
+
+	// unknown format
+	return append(dst, '%', fmt)
+}
+
+// An unrounded represents an unrounded value: the value scaled by 4,
+// with the low bit set if any nonzero bits were discarded.
+type unrounded uint64
+
+func (u unrounded) floor() uint64         { return uint64((u + 0) >> 2) }
+func (u unrounded) round() uint64         { return uint64((u + 1 + (u>>2)&1) >> 2) }
+func (u unrounded) ceil() uint64          { return uint64((u + 3) >> 2) }
+func (u unrounded) nudge(δ int) unrounded { return u + unrounded(δ) }
+
+// log10Pow2(x) returns ⌊log₁₀ 2**x⌋ = ⌊x * log₁₀ 2⌋.
+func log10Pow2(x int) int {
+	// log₁₀ 2 ≈ 0.30102999566 ≈ 78913 / 2^18
+	return (x * 78913) >> 18
+}
+
+// log2Pow10(x) returns ⌊log₂ 10**x⌋ = ⌊x * log₂ 10⌋.
+func log2Pow10(x int) int {
+	// log₂ 10 ≈ 3.32192809489 ≈ 108853 / 2^15
+	return (x * 108853) >> 15
+}
+
+// skewed computes the skewed footprint of m * 2**e,
+// which is ⌊log₁₀ 3/4 * 2**e⌋ = ⌊e*(log₁₀ 2)-(log₁₀ 4/3)⌋.
+func skewed(e int) int {
+	return (e*631305 - 261663) >> 21
+}
+
+// shortFloat computes the shortest formatting of f = m * 2**e as d * 10**p,
+// using as few digits as possible that will still round trip back to the
+// original float. m must be left-justified so its high bit is set.
+func shortFloat(m uint64, e, mantBits, minExp int) (d uint64, p int) {
+	var min, max uint64
+	var odd int
+	z := 63 - mantBits
+	if m == 1<<63 && e > minExp {
+		// A power of two: the gap to the float below is half the gap above.
+		p = -skewed(e + z)
+		min = m - 1<<uint(z-2) // min = m - 1/4 * 2**(e+z)
+		max = m + 1<<uint(z-1) // max = m + 1/2 * 2**(e+z)
+	} else {
+		if e < minExp {
+			// Denormal.
+			z = z + (minExp - e)
+		}
+		p = -log10Pow2(e + z)
+		min = m - 1<<uint(z-1) // min = m - 1/2 * 2**(e+z)
+		max = m + 1<<uint(z-1) // max = m + 1/2 * 2**(e+z)
+	}
+	odd = int(m>>uint(z)) & 1
+
+	var pre scaler
+	prescale(&pre, e, p, log2Pow10(p))
+	dmin := uscale(min, &pre).nudge(+odd).ceil()
+	dmax := uscale(max, &pre).nudge(-odd).floor()
+
+	if d = dmax / 10; d*10 >= dmin {
+		return d, -(p - 1)
+	}
+	if d = dmin; d < dmax {
+		d = uscale(m, &pre).round()
+	}
+	return d, -p
+}
+
+// A pmHiLo represents hi<<64 - lo.
+type pmHiLo struct {
+	hi uint64
+	lo uint64
+}
+
+// A scaler holds derived scaling constants for a given e, p pair.
+type scaler struct {
+	pmHi uint64
+	pmLo uint64
+	s    uint
+}
+
+// prescale sets the scaling constants for e, p.
+// lp must be log2Pow10(p).
+func prescale(pre *scaler, e, p, lp int) {
+	pre.pmHi = pow10Tab[p-pow10Min].hi
+	pre.pmLo = pow10Tab[p-pow10Min].lo
+	pre.s = uint(-(e + lp + 3))
+}
+
+// uscale returns unround(x * 2**e * 10**p).
+// The caller should pass &pre for prescale(&pre, e, p, log2Pow10(p))
+// and should have left-justified x so its high bit is set.
+func uscale(x uint64, c *scaler) unrounded {
+	hi, mid := bits.Mul64(x, c.pmHi)
+	s := c.s & 63 // make shifts cheaper
+	if hi>>s<<s != hi {
+		return unrounded(hi>>s | 1)
+	}
+	mid2, _ := bits.Mul64(x, c.pmLo)
+	if mid < mid2 {
+		hi--
+	}
+	u := unrounded(hi >> s)
+	if mid-mid2 > 1 {
+		u |= 1
+	}
+	return u
+}
+
+// setDigits writes the nd digits of d into s and returns the position of
+// the decimal point for d * 10**p and the digit count without trailing zeros.
+func setDigits(s []byte, d uint64, p, nd int) (dp, nzd int) {
+	for i := nd - 1; i >= 0; i-- {
+		s[i] = byte('0' + d%10)
+		d /= 10
+	}
+	dp = nd + p
+	for nd > 0 && s[nd-1] == '0' {
+		nd--
+	}
+	return dp, nd
+}
+
+// numDigits returns the number of decimal digits in d.
+// It requires d ≥ 1.
+func numDigits(d uint64) int {
+	nd := log10Pow2(bits.Len64(d))
+	if d >= uint64pow10[nd] {
+		nd++
+	}
+	return nd
+}
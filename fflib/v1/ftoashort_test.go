@@ -0,0 +1,152 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"flag"
+	"math"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+var exhaustive = flag.Bool("exhaustive", false, "check every float32 in TestFloatShortestExhaustive32")
+
+var shortestFormats = []byte{'e', 'E', 'f', 'g', 'G'}
+
+func testShortest(t *testing.T, f float64, bitSize int) {
+	var scratch, expect [400]byte
+	for _, fmt := range shortestFormats {
+		got := AppendFloatShortest(scratch[:0], f, fmt, bitSize)
+		want := strconv.AppendFloat(expect[:0], f, fmt, -1, bitSize)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("AppendFloatShortest(%v, %c, %d): got %s, expected %s", f, fmt, bitSize, got, want)
+		}
+	}
+}
+
+func TestFloatShortestSpecial(t *testing.T) {
+	values := []float64{
+		0, math.Copysign(0, -1), 1, -1, 0.1, 0.3, 1e21, 1e-7, 123456789,
+		math.MaxFloat64, math.SmallestNonzeroFloat64, math.MaxFloat32, math.SmallestNonzeroFloat32,
+		math.Inf(1), math.Inf(-1), math.NaN(),
+		5e-324, 1.7976931348623157e308, 2.2250738585072014e-308, 2.225073858507201e-308,
+		9007199254740993, 1 << 53, 1 << 63, 0.000001, 100000, 1e23, 8.41e21, 4.9406564584124654e-324,
+	}
+
+	for _, v := range values {
+		testShortest(t, v, 64)
+		testShortest(t, v, 32)
+	}
+
+	for e := -1074; e <= 1023; e++ {
+		testShortest(t, math.Ldexp(1, e), 64)
+		testShortest(t, math.Nextafter(math.Ldexp(1, e), 0), 64)
+	}
+}
+
+func TestFloatShortestRandom64(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	n := 1000000
+	if testing.Short() {
+		n = 10000
+	}
+
+	for i := 0; i < n; i++ {
+		f := math.Float64frombits(r.Uint64())
+		testShortest(t, f, 64)
+	}
+
+	// Values with few significant digits hit the short-output paths.
+	for i := 0; i < n/10; i++ {
+		f := float64(r.Int63n(1000000)) * math.Pow10(r.Intn(40)-20)
+		testShortest(t, f, 64)
+	}
+}
+
+// TestFloatShortestExhaustive32 checks float32 bit patterns against
+// strconv, and that the output parses back to the same float32.
+// Checking all 2^32 of them takes several minutes per core, so by default
+// only a spread out sample is checked; run with -exhaustive for all of them.
+func TestFloatShortestExhaustive32(t *testing.T) {
+	step := uint64(4093)
+	if *exhaustive {
+		step = 1
+	} else if testing.Short() {
+		step = 65521
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	chunk := (uint64(1)<<32)/uint64(workers) + 1
+	failed := make(chan string, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(start, end uint64) {
+			defer wg.Done()
+			var scratch, expect [64]byte
+			for i := start; i < end && i < 1<<32; i += step {
+				f := math.Float32frombits(uint32(i))
+				got := AppendFloatShortest(scratch[:0], float64(f), 'e', 32)
+				want := strconv.AppendFloat(expect[:0], float64(f), 'e', -1, 32)
+				if !bytes.Equal(got, want) {
+					failed <- "0x" + strconv.FormatUint(i, 16) + ": got " + string(got) + ", expected " + string(want)
+					return
+				}
+				if f == f && !math.IsInf(float64(f), 0) {
+					back, err := strconv.ParseFloat(string(got), 32)
+					if err != nil || math.Float32bits(float32(back)) != uint32(i) {
+						failed <- "0x" + strconv.FormatUint(i, 16) + ": " + string(got) + " does not round trip"
+						return
+					}
+				}
+			}
+		}(uint64(w)*chunk, uint64(w+1)*chunk)
+	}
+	wg.Wait()
+	close(failed)
+
+	for msg := range failed {
+		t.Error(msg)
+	}
+}
+
+func TestWriteFloat(t *testing.T) {
+	var buf Buffer
+	WriteFloat(&buf, -0.000123, 'g', 64)
+	if buf.String() != "-0.000123" {
+		t.Fatalf("unexpected WriteFloat output: %s", buf.String())
+	}
+
+	buf.Reset()
+	AppendFloat(&buf, 1.1, 'f', -1, 32)
+	if buf.String() != "1.1" {
+		t.Fatalf("unexpected AppendFloat output: %s", buf.String())
+	}
+}
+
+func BenchmarkWriteFloat(b *testing.B) {
+	var buf Buffer
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		WriteFloat(&buf, 3.1415926535, 'g', 64)
+	}
+}
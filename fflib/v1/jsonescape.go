@@ -0,0 +1,200 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Escaping selects how WriteJson escapes strings. It is set on the buffer
+// generated code writes to, see EscapingBuffer.
+type Escaping uint8
+
+const (
+	// EscapeHTML escapes like encoding/json does by default: '"', '\\',
+	// control characters, '<', '>', '&', U+2028 and U+2029.
+	// This is the default.
+	EscapeHTML Escaping = iota
+
+	// EscapeMinimal only escapes what RFC 8259 requires: '"', '\\' and
+	// control characters.
+	EscapeMinimal
+
+	// EscapeASCII escapes like EscapeHTML, and also every non-ASCII rune
+	// as \uXXXX, with surrogate pairs outside the Basic Multilingual Plane.
+	// The output is plain ASCII.
+	EscapeASCII
+
+	escapeModeMask Escaping = 0x0f
+)
+
+// RejectInvalidUTF8 can be combined with any of the modes above. Invalid
+// UTF-8 is replaced by U+FFFD in any case, but with RejectInvalidUTF8 the
+// buffer also records ErrInvalidUTF8, which encoders return.
+const RejectInvalidUTF8 Escaping = 0x80
+
+// ErrInvalidUTF8 is recorded by WriteJson when a string with invalid UTF-8
+// is written with RejectInvalidUTF8.
+var ErrInvalidUTF8 = errors.New("fflib.v1: string with invalid UTF-8")
+
+// Mode returns e without the RejectInvalidUTF8 flag.
+func (e Escaping) Mode() Escaping {
+	return e & escapeModeMask
+}
+
+// EscapingBuffer is implemented by the buffers of this package, which carry
+// the Escaping that WriteJson uses for strings written to them.
+type EscapingBuffer interface {
+	JsonStringWriter
+	Escaping() Escaping
+	// SetEscaping selects e, and clears the error returned by EscapeError.
+	SetEscaping(e Escaping)
+	// EscapeError returns ErrInvalidUTF8 if a string with invalid UTF-8 was
+	// written with RejectInvalidUTF8 since SetEscaping was called.
+	EscapeError() error
+	setEscapeError(err error)
+}
+
+func (b *Buffer) Escaping() Escaping {
+	return b.escaping
+}
+
+func (b *Buffer) SetEscaping(e Escaping) {
+	b.escaping = e
+	b.escapeErr = nil
+}
+
+func (b *Buffer) EscapeError() error {
+	return b.escapeErr
+}
+
+func (b *Buffer) setEscapeError(err error) {
+	if b.escapeErr == nil {
+		b.escapeErr = err
+	}
+}
+
+// writeJsonEscaping is WriteJson for any Escaping but the default.
+func writeJsonEscaping(buf EscapingBuffer, s []byte, e Escaping) {
+	mode := e.Mode()
+	html := mode != EscapeMinimal
+
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '\\' && b != '"' && (!html || (b != '<' && b != '>' && b != '&')) {
+				i++
+				continue
+			}
+			if start < i {
+				buf.Write(s[start:i])
+			}
+			switch b {
+			case '\\', '"':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\n':
+				buf.WriteByte('\\')
+				buf.WriteByte('n')
+			case '\r':
+				buf.WriteByte('\\')
+				buf.WriteByte('r')
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[b>>4])
+				buf.WriteByte(hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		c, size := utf8.DecodeRune(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			if start < i {
+				buf.Write(s[start:i])
+			}
+			if e&RejectInvalidUTF8 != 0 {
+				buf.setEscapeError(ErrInvalidUTF8)
+			}
+			buf.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		if mode == EscapeASCII || (html && (c == '\u2028' || c == '\u2029')) {
+			if start < i {
+				buf.Write(s[start:i])
+			}
+			if c >= 0x10000 {
+				r1, r2 := utf16.EncodeRune(c)
+				writeEscapedRune(buf, r1)
+				writeEscapedRune(buf, r2)
+			} else {
+				writeEscapedRune(buf, c)
+			}
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	if start < len(s) {
+		buf.Write(s[start:])
+	}
+	buf.WriteByte('"')
+}
+
+func writeEscapedRune(buf JsonStringWriter, c rune) {
+	buf.WriteString(`\u`)
+	buf.WriteByte(hex[c>>12&0xF])
+	buf.WriteByte(hex[c>>8&0xF])
+	buf.WriteByte(hex[c>>4&0xF])
+	buf.WriteByte(hex[c&0xF])
+}
+
+// escapeASCII appends s to dst with every non-ASCII rune escaped as
+// \uXXXX. s must be JSON, where non-ASCII runes can only occur in strings.
+func escapeASCII(dst *Buffer, s []byte) {
+	start := 0
+	for i := 0; i < len(s); {
+		if s[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		if start < i {
+			dst.Write(s[start:i])
+		}
+		c, size := utf8.DecodeRune(s[i:])
+		if c >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(c)
+			writeEscapedRune(dst, r1)
+			writeEscapedRune(dst, r2)
+		} else {
+			writeEscapedRune(dst, c)
+		}
+		i += size
+		start = i
+	}
+	if start < len(s) {
+		dst.Write(s[start:])
+	}
+}
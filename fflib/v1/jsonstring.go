@@ -46,6 +46,13 @@ func WriteJsonString(buf JsonStringWriter, s string) {
  * Function ported from encoding/json: func (e *encodeState) string(s string) (int, error)
  */
 func WriteJson(buf JsonStringWriter, s []byte) {
+	if eb, ok := buf.(EscapingBuffer); ok {
+		if e := eb.Escaping(); e != EscapeHTML {
+			writeJsonEscaping(eb, s, e)
+			return
+		}
+	}
+
 	buf.WriteByte('"')
 	start := 0
 	for i := 0; i < len(s); {
@@ -36,3 +36,67 @@ func TestWriteJsonString(t *testing.T) {
 	}
 	// TODO(pquerna): all them important tests.
 }
+
+func TestWriteJsonEscaping(t *testing.T) {
+	in := "a<b>&c\u2028\u00e9\U0001F600\"\\\n\x01"
+	tests := []struct {
+		e      Escaping
+		expect string
+	}{
+		{EscapeHTML, `"a\u003cb\u003e\u0026c\u2028` + "\u00e9\U0001F600" + `\"\\\n\u0001"`},
+		{EscapeMinimal, `"a<b>&c` + "\u2028\u00e9\U0001F600" + `\"\\\n\u0001"`},
+		{EscapeASCII, `"a\u003cb\u003e\u0026c\u2028\u00e9\ud83d\ude00\"\\\n\u0001"`},
+	}
+
+	for _, test := range tests {
+		var buf Buffer
+		buf.SetEscaping(test.e)
+		WriteJsonString(&buf, in)
+		if buf.String() != test.expect {
+			t.Fatalf("Escaping %d: expected %s, got %s", test.e, test.expect, buf.String())
+		}
+		if buf.EscapeError() != nil {
+			t.Fatalf("Escaping %d: unexpected error %v", test.e, buf.EscapeError())
+		}
+	}
+}
+
+func TestWriteJsonInvalidUTF8(t *testing.T) {
+	var buf Buffer
+	buf.SetEscaping(EscapeMinimal)
+	WriteJsonString(&buf, "a\xffb")
+	if buf.String() != `"a\ufffdb"` || buf.EscapeError() != nil {
+		t.Fatalf("expected invalid UTF-8 to be replaced, got %s, %v", buf.String(), buf.EscapeError())
+	}
+
+	buf.Reset()
+	buf.SetEscaping(EscapeHTML | RejectInvalidUTF8)
+	WriteJsonString(&buf, "a\xffb")
+	if buf.EscapeError() != ErrInvalidUTF8 {
+		t.Fatalf("expected ErrInvalidUTF8, got %v", buf.EscapeError())
+	}
+	buf.SetEscaping(EscapeHTML | RejectInvalidUTF8)
+	if buf.EscapeError() != nil {
+		t.Fatalf("expected SetEscaping to clear the error")
+	}
+}
+
+func TestBufferEncodeEscaping(t *testing.T) {
+	v := map[string]string{"k": "<\u00e9>"}
+	expect := map[Escaping]string{
+		EscapeHTML:    `{"k":"\u003c` + "\u00e9" + `\u003e"}`,
+		EscapeMinimal: `{"k":"<` + "\u00e9" + `>"}`,
+		EscapeASCII:   `{"k":"\u003c\u00e9\u003e"}`,
+	}
+	for e, s := range expect {
+		var buf Buffer
+		buf.WriteByte('[')
+		buf.SetEscaping(e)
+		if err := buf.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != "["+s {
+			t.Fatalf("Escaping %d: expected [%s, got %s", e, s, buf.String())
+		}
+	}
+}
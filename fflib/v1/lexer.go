@@ -35,6 +35,8 @@
 package v1
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -101,10 +103,21 @@ type FFLexer struct {
 	Token    FFTok
 	Error    FFErr
 	BigError error
+	// UseNumber makes values decoded through UnmarshalFallback
+	// use json.Number instead of float64 for numbers in interfaces,
+	// like json.Decoder.UseNumber.
+	UseNumber bool
+	// MergePatch makes generated UnmarshalJSONFFLexer methods apply the
+	// input as an RFC 7396 merge patch, see MergeJSONPatch: null resets
+	// fields to their zero value, and objects are merged into maps with
+	// string keys instead of replacing them.
+	MergePatch bool
 	// TODO: convert all of this to an interface
 	lastCurrentChar int
 	captureAll      bool
 	buf             Buffer
+	// input offset of the first byte of the last token scanned
+	tokenStart int
 }
 
 func NewFFLexer(input []byte) *FFLexer {
@@ -119,6 +132,16 @@ func NewFFLexer(input []byte) *FFLexer {
 	return fl
 }
 
+// NewFFLexerReader returns a lexer that reads its input from r as it goes,
+// through a window that only has to hold the current token. Generated
+// UnmarshalJSONFFLexer methods decode from it like from any other lexer.
+// The lexer may read past the end of the value it decodes.
+func NewFFLexerReader(r io.Reader) *FFLexer {
+	fl := NewFFLexer(nil)
+	fl.reader.ResetReader(r)
+	return fl
+}
+
 type LexerError struct {
 	offset int
 	line   int
@@ -131,11 +154,20 @@ func (ffl *FFLexer) Reset(input []byte) {
 	ffl.Token = FFTok_init
 	ffl.Error = FFErr_e_ok
 	ffl.BigError = nil
+	ffl.captureAll = false
 	ffl.reader.Reset(input)
 	ffl.lastCurrentChar = 0
+	ffl.tokenStart = 0
 	ffl.Output.Reset()
 }
 
+// ResetReader resets the Lexer to read new input from r,
+// see NewFFLexerReader.
+func (ffl *FFLexer) ResetReader(r io.Reader) {
+	ffl.Reset(nil)
+	ffl.reader.ResetReader(r)
+}
+
 func (le *LexerError) Error() string {
 	return fmt.Sprintf(`ffjson error: (%T)%s offset=%d line=%d char=%d`,
 		le.err, le.err.Error(),
@@ -153,6 +185,34 @@ func (ffl *FFLexer) WrapErr(err error) error {
 	}
 }
 
+// InputOffset returns the offset in the input just after the last token
+// scanned, counting from the start of the stream when reading from an
+// io.Reader.
+func (ffl *FFLexer) InputOffset() int {
+	return ffl.reader.Pos()
+}
+
+// TokenStart returns the input offset of the first byte of the last token
+// scanned. With InputOffset after SkipField, it gives the extent of a value
+// in the input.
+func (ffl *FFLexer) TokenStart() int {
+	return ffl.tokenStart
+}
+
+// ExpectEnd returns an error unless only whitespace is left in the input
+// after the last token, as encoding/json requires after a top-level value.
+func (ffl *FFLexer) ExpectEnd() error {
+	c, err := ffl.reader.ReadByteNoWS()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return ffl.WrapErr(err)
+	}
+	ffl.reader.UnreadByte()
+	return ffl.WrapErr(fmt.Errorf("ffjson: invalid character %q after top-level value", c))
+}
+
 func (ffl *FFLexer) scanReadByte() (byte, error) {
 	var c byte
 	var err error
@@ -168,6 +228,10 @@ func (ffl *FFLexer) scanReadByte() (byte, error) {
 		return 0, err
 	}
 
+	// c starts a token, anything before it may be dropped from the window.
+	ffl.reader.MarkLastByte()
+	ffl.tokenStart = ffl.reader.Pos() - 1
+
 	return c, nil
 }
 
@@ -286,6 +350,7 @@ func (ffl *FFLexer) lexNumber() FFTok {
 	var numRead int = 0
 	tok := FFTok_integer
 	startPos := ffl.reader.Pos()
+	eof := false
 
 	c, err := ffl.readByte()
 	if err != nil {
@@ -294,7 +359,7 @@ func (ffl *FFLexer) lexNumber() FFTok {
 
 	/* optional leading minus */
 	if c == '-' {
-		c, err = ffl.readByte()
+		c, eof, err = ffl.numByte()
 		if err != nil {
 			return FFTok_error
 		}
@@ -302,40 +367,44 @@ func (ffl *FFLexer) lexNumber() FFTok {
 
 	/* a single zero, or a series of integers */
 	if c == '0' {
-		c, err = ffl.readByte()
+		c, eof, err = ffl.numByte()
 		if err != nil {
 			return FFTok_error
 		}
 	} else if c >= '1' && c <= '9' {
 		for c >= '0' && c <= '9' {
-			c, err = ffl.readByte()
+			c, eof, err = ffl.numByte()
 			if err != nil {
 				return FFTok_error
 			}
 		}
 	} else {
-		ffl.unreadByte()
+		if !eof {
+			ffl.unreadByte()
+		}
 		ffl.Error = FFErr_missing_integer_after_minus
 		return FFTok_error
 	}
 
 	if c == '.' {
 		numRead = 0
-		c, err = ffl.readByte()
+		c, eof, err = ffl.numByte()
 		if err != nil {
 			return FFTok_error
 		}
 
 		for c >= '0' && c <= '9' {
 			numRead++
-			c, err = ffl.readByte()
+			c, eof, err = ffl.numByte()
 			if err != nil {
 				return FFTok_error
 			}
 		}
 
 		if numRead == 0 {
-			ffl.unreadByte()
+			if !eof {
+				ffl.unreadByte()
+			}
 
 			ffl.Error = FFErr_missing_integer_after_decimal
 			return FFTok_error
@@ -347,14 +416,14 @@ func (ffl *FFLexer) lexNumber() FFTok {
 	/* optional exponent (indicates this is floating point) */
 	if c == 'e' || c == 'E' {
 		numRead = 0
-		c, err = ffl.readByte()
+		c, eof, err = ffl.numByte()
 		if err != nil {
 			return FFTok_error
 		}
 
 		/* optional sign */
 		if c == '+' || c == '-' {
-			c, err = ffl.readByte()
+			c, eof, err = ffl.numByte()
 			if err != nil {
 				return FFTok_error
 			}
@@ -362,7 +431,7 @@ func (ffl *FFLexer) lexNumber() FFTok {
 
 		for c >= '0' && c <= '9' {
 			numRead++
-			c, err = ffl.readByte()
+			c, eof, err = ffl.numByte()
 			if err != nil {
 				return FFTok_error
 			}
@@ -376,13 +445,31 @@ func (ffl *FFLexer) lexNumber() FFTok {
 		tok = FFTok_double
 	}
 
-	ffl.unreadByte()
+	if !eof {
+		ffl.unreadByte()
+	}
 
 	endPos := ffl.reader.Pos()
 	ffl.Output.Write(ffl.reader.Slice(startPos, endPos))
 	return tok
 }
 
+// numByte reads the next byte of a number. The end of the input ends a
+// number like any other byte that cannot be part of it, so it is not an
+// error here: eof is set and 0 returned instead.
+func (ffl *FFLexer) numByte() (c byte, eof bool, err error) {
+	c, err = ffl.reader.ReadByte()
+	if err == io.EOF {
+		return 0, true, nil
+	}
+	if err != nil {
+		ffl.Error = FFErr_io
+		ffl.BigError = err
+		return 0, false, err
+	}
+	return c, false, nil
+}
+
 var true_bytes = []byte{'r', 'u', 'e'}
 var false_bytes = []byte{'a', 'l', 's', 'e'}
 var null_bytes = []byte{'u', 'l', 'l'}
@@ -441,7 +528,7 @@ func (ffl *FFLexer) Scan() FFTok {
 				ffl.Output.WriteByte(':')
 			}
 			goto lexed
-		case '\t', '\n', '\v', '\f', '\r', ' ':
+		case '\t', '\n', '\r', ' ':
 			if ffl.captureAll {
 				ffl.Output.WriteByte(c)
 			}
@@ -507,8 +594,10 @@ func (ffl *FFLexer) scanField(start FFTok, capture bool) ([]byte, error) {
 				//fmt.Printf("capture-token: %v end: %v depth: %v\n", tok, end, depth)
 				switch tok {
 				case FFTok_eof:
+					ffl.captureAll = false
 					return nil, errors.New("ffjson: unexpected EOF")
 				case FFTok_error:
+					ffl.captureAll = false
 					if ffl.BigError != nil {
 						return nil, ffl.BigError
 					}
@@ -565,9 +654,48 @@ func (ffl *FFLexer) CaptureField(start FFTok) ([]byte, error) {
 	return ffl.scanField(start, true)
 }
 
+// Skips an entire field value, including recursive objects.
+// Nested objects and arrays are validated but not tokenized, see
+// ffReader.SkipContainer.
 func (ffl *FFLexer) SkipField(start FFTok) error {
-	_, err := ffl.scanField(start, false)
-	return err
+	var open byte
+	switch start {
+	case FFTok_left_bracket:
+		open = '{'
+	case FFTok_left_brace:
+		open = '['
+	case FFTok_bool,
+		FFTok_integer,
+		FFTok_null,
+		FFTok_double,
+		FFTok_string:
+		// simple value, already consumed.
+		return nil
+	default:
+		return fmt.Errorf("ffjson: invalid capture type: %v", start)
+	}
+
+	err := ffl.reader.SkipContainer(open)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = errors.New("ffjson: unexpected EOF")
+		}
+		ffl.BigError = err
+		return err
+	}
+	return nil
+}
+
+// UnmarshalFallback decodes a field captured with CaptureField using
+// encoding/json. It is used by generated code for types it cannot
+// handle itself, and honors UseNumber.
+func (ffl *FFLexer) UnmarshalFallback(data []byte, v interface{}) error {
+	if !ffl.UseNumber {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
 }
 
 // TODO(pquerna): return line number and offset.
@@ -20,8 +20,12 @@ package v1
 import (
 	"bytes"
 	"errors"
+	"io"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func scanAll(ffl *FFLexer) []FFTok {
@@ -268,6 +272,25 @@ func TestInt(t *testing.T) {
 	tInt(t, `{"a": -0}`, -0)
 }
 
+func TestNumberAtEOF(t *testing.T) {
+	for _, input := range []string{"0", "-12", "1.5", "2e10"} {
+		ffl := NewFFLexer([]byte(input))
+		if tok := ffl.Scan(); tok != FFTok_integer && tok != FFTok_double || ffl.Output.String() != input {
+			t.Fatalf("expected number %s, got %v %s", input, tok, ffl.Output.String())
+		}
+		if tok := ffl.Scan(); tok != FFTok_eof {
+			t.Fatalf("expected EOF after %s, got %v", input, tok)
+		}
+	}
+
+	for _, input := range []string{"-", "1.", "2e"} {
+		ffl := NewFFLexer([]byte(input))
+		if tok := ffl.Scan(); tok != FFTok_error {
+			t.Fatalf("expected error for %s, got %v", input, tok)
+		}
+	}
+}
+
 func tError(t *testing.T, input string, targetCount int, targetError FFErr) {
 	ffl := NewFFLexer([]byte(input))
 	count, err := scanToTokCount(ffl, FFTok_error)
@@ -325,3 +348,205 @@ func TestCapture(t *testing.T) {
 		t.Fatalf("didnt capture subfield: buf: %v", string(buf))
 	}
 }
+
+func TestSkipField(t *testing.T) {
+	valid := []string{
+		`{}`,
+		`[]`,
+		`{"a": [1, 2.5e-3, true, false, null], "b": {"c": "}]\"\\é"}}`,
+		`[[[{"x": []}]], "[", "{", -0]`,
+		"{\"a\": /* a } comment */ 1, // ] another\n \"b\": 2}",
+	}
+
+	for _, v := range valid {
+		ffl := NewFFLexer([]byte(`{"skip": ` + v + `, "next": 1}`))
+		err := scanToTok(ffl, FFTok_colon)
+		if err != nil {
+			t.Fatalf("scanToTok failed: %v", err)
+		}
+
+		tok := ffl.Scan()
+		err = ffl.SkipField(tok)
+		if err != nil {
+			t.Fatalf("SkipField failed for %s: %v", v, err)
+		}
+
+		if tok = ffl.Scan(); tok != FFTok_comma {
+			t.Fatalf("expected comma after skipping %s, got %v", v, tok)
+		}
+		if tok = ffl.Scan(); tok != FFTok_string || ffl.Output.String() != "next" {
+			t.Fatalf("expected next key after skipping %s, got %v %s", v, tok, ffl.Output.String())
+		}
+	}
+
+	invalid := []string{
+		`{`,
+		`[1, 2`,
+		`{"a": [}`,
+		`[{]}`,
+		`{"a": "unterminated}`,
+		`{"a": "bad \x escape"}`,
+		"{\"a\": \"control \x01 char\"}",
+		`{"a": @}`,
+		`[1, /* unterminated ]`,
+		`{"a": "\`,
+	}
+
+	for _, v := range invalid {
+		ffl := NewFFLexer([]byte(v))
+		tok := ffl.Scan()
+		err := ffl.SkipField(tok)
+		if err == nil {
+			t.Fatalf("expected SkipField error for %s", v)
+		}
+	}
+}
+
+var skipInput = []byte(`{"id": 1, "payload": [` + strings.Repeat(`{"user": {"name": "Jane Doe", "tags": ["a", "b", "c"], "bio": "Likes \"quotes\" and \\backslashes\\ and unicode é."}, "scores": [1.5, 2.25, -3e10, 4, 5, 6, 7, 8], "active": true, "nothing": null}, `, 50) + `{}]}`)
+
+func BenchmarkSkipField(b *testing.B) {
+	b.SetBytes(int64(len(skipInput)))
+	ffl := NewFFLexer(skipInput)
+	for i := 0; i < b.N; i++ {
+		ffl.Reset(skipInput)
+		scanToTok(ffl, FFTok_colon)
+		scanToTok(ffl, FFTok_colon)
+		if err := ffl.SkipField(ffl.Scan()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// scanAllOutput is scanAll, also collecting the Output of every token.
+func scanAllOutput(ffl *FFLexer) ([]FFTok, []string) {
+	var toks []FFTok
+	var outs []string
+	for {
+		tok := ffl.Scan()
+		toks = append(toks, tok)
+		outs = append(outs, ffl.Output.String())
+		if tok == FFTok_eof || tok == FFTok_error {
+			break
+		}
+	}
+	return toks, outs
+}
+
+func newTestReaderLexer(r io.Reader, window int) *FFLexer {
+	ffl := NewFFLexer(nil)
+	ffl.reader.window = make([]byte, window)
+	ffl.ResetReader(r)
+	return ffl
+}
+
+var streamInputs = []string{
+	`{"hello": "world", "n": [1, -2.5, 3e10, 0], "t": true, "f": false, "z": null}`,
+	`{"long": "` + strings.Repeat("abcdefghij", 20) + `", "esc": "\"\\\/\b\f\n\r\t€𐐷 \u20AC\uD801\uDC37 end"}`,
+	`{"bad": "escape \uD801\u12"}`,
+	"[1, /* a comment */ 2, // another\n 3]\n\n\t  ",
+	`{"skip": {"a": [1, {"b": "]}\"€"}, null]}, "after": 1}`,
+	`{"bad": "unterminated`,
+	`{"bad": 1.e5}`,
+	"[\"x\",\n\"ctrl \x01\"]",
+}
+
+func TestReaderStreaming(t *testing.T) {
+	for _, in := range streamInputs {
+		wantToks, wantOuts := scanAllOutput(NewFFLexer([]byte(in)))
+		wantLine, wantChar := 0, 0
+
+		for window := 1; window <= 20; window++ {
+			for _, r := range []io.Reader{strings.NewReader(in), iotest.OneByteReader(strings.NewReader(in))} {
+				ffl := newTestReaderLexer(r, window)
+				toks, outs := scanAllOutput(ffl)
+				if toks[len(toks)-1] == FFTok_error {
+					// Output is unspecified after an error.
+					outs[len(outs)-1] = wantOuts[len(wantOuts)-1]
+				}
+				if !reflect.DeepEqual(toks, wantToks) || !reflect.DeepEqual(outs, wantOuts) {
+					t.Fatalf("window %d: streaming %q\ngot:      %v %q\nexpected: %v %q", window, in, toks, outs, wantToks, wantOuts)
+				}
+
+				line, char := ffl.reader.PosWithLine()
+				if wantLine == 0 {
+					ref := NewFFLexer([]byte(in))
+					scanAll(ref)
+					wantLine, wantChar = ref.reader.PosWithLine()
+				}
+				if line != wantLine || char != wantChar {
+					t.Fatalf("window %d: position in %q: got line %d char %d, expected line %d char %d", window, in, line, char, wantLine, wantChar)
+				}
+			}
+		}
+	}
+}
+
+func TestReaderStreamingSkipField(t *testing.T) {
+	in := `{"skip": {"a": [1, {"b": "]}\"€\u20AC"}, null], /* } */ "c": {}}, "after": 1}`
+	for window := 1; window <= 20; window++ {
+		ffl := newTestReaderLexer(iotest.OneByteReader(strings.NewReader(in)), window)
+		if err := scanToTok(ffl, FFTok_colon); err != nil {
+			t.Fatalf("scanToTok failed: %v", err)
+		}
+		if err := ffl.SkipField(ffl.Scan()); err != nil {
+			t.Fatalf("window %d: SkipField failed: %v", window, err)
+		}
+		if tok := ffl.Scan(); tok != FFTok_comma {
+			t.Fatalf("window %d: expected comma after skipping, got %v", window, tok)
+		}
+		if tok := ffl.Scan(); tok != FFTok_string || ffl.Output.String() != "after" {
+			t.Fatalf("window %d: expected next key after skipping, got %v %s", window, tok, ffl.Output.String())
+		}
+	}
+}
+
+// repeatReader returns an array of n copies of elem, without holding it in memory.
+type repeatReader struct {
+	elem []byte
+	n    int
+	pos  int
+	done bool
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+	if r.n == 0 {
+		r.done = true
+		return copy(p, "0]"), nil
+	}
+	n := copy(p, r.elem[r.pos:])
+	r.pos += n
+	if r.pos == len(r.elem) {
+		r.pos = 0
+		r.n--
+	}
+	return n, nil
+}
+
+func TestReaderStreamingBoundedWindow(t *testing.T) {
+	elem := []byte(`{"user": {"name": "Jane Doe", "tags": ["a", "b"]}, "scores": [1.5, -3e10]}, `)
+	const n = 100000
+
+	ffl := NewFFLexerReader(io.MultiReader(strings.NewReader("["), &repeatReader{elem: elem, n: n}))
+	if err := ffl.SkipField(ffl.Scan()); err != nil {
+		t.Fatalf("SkipField failed: %v", err)
+	}
+	if tok := ffl.Scan(); tok != FFTok_eof {
+		t.Fatalf("expected EOF, got %v", tok)
+	}
+	if len(ffl.reader.window) != defaultWindowSize {
+		t.Fatalf("window grew to %d bytes while skipping %d bytes", len(ffl.reader.window), n*len(elem))
+	}
+
+	// A single token larger than the window grows it.
+	big := `"` + strings.Repeat("x", 3*defaultWindowSize) + `" 12345`
+	ffl.ResetReader(strings.NewReader(big))
+	if tok := ffl.Scan(); tok != FFTok_string || ffl.Output.Len() != 3*defaultWindowSize {
+		t.Fatalf("expected long string, got %v of %d bytes", tok, ffl.Output.Len())
+	}
+	if tok := ffl.Scan(); tok != FFTok_integer || ffl.Output.String() != "12345" {
+		t.Fatalf("expected number after long string, got %v %s", tok, ffl.Output.String())
+	}
+}
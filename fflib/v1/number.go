@@ -0,0 +1,149 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+/* Portions of this file are on Go stdlib's encoding/json/encode.go */
+// Copyright 2010 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// IsValidNumber reports whether s is a valid JSON number literal.
+func IsValidNumber(s []byte) bool {
+	// This function implements the JSON numbers grammar.
+	// See https://tools.ietf.org/html/rfc7159#section-6
+	// and https://www.json.org/img/number.png
+
+	if len(s) == 0 {
+		return false
+	}
+
+	// Optional -
+	if s[0] == '-' {
+		s = s[1:]
+		if len(s) == 0 {
+			return false
+		}
+	}
+
+	// Digits
+	switch {
+	default:
+		return false
+
+	case s[0] == '0':
+		s = s[1:]
+
+	case '1' <= s[0] && s[0] <= '9':
+		s = s[1:]
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	}
+
+	// . followed by 1 or more digits.
+	if len(s) >= 2 && s[0] == '.' && '0' <= s[1] && s[1] <= '9' {
+		s = s[2:]
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	}
+
+	// e or E followed by an optional - or + and
+	// 1 or more digits.
+	if len(s) >= 2 && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s[0] == '+' || s[0] == '-' {
+			s = s[1:]
+			if len(s) == 0 {
+				return false
+			}
+		}
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	}
+
+	// Make sure we are at the end.
+	return len(s) == 0
+}
+
+// WriteJsonNumber writes the json.Number string n as a JSON number literal.
+// Like encoding/json, an empty string is written as 0 and anything else
+// that is not a valid number literal is an error.
+func WriteJsonNumber(buf EncodingBuffer, n string) error {
+	if n == "" {
+		return buf.WriteByte('0')
+	}
+	if !IsValidNumber([]byte(n)) {
+		return fmt.Errorf("json: invalid number literal %q", n)
+	}
+	_, err := buf.WriteString(n)
+	return err
+}
+
+// WriteBigInt writes v as a JSON number, the same way (*big.Int).MarshalJSON does.
+func WriteBigInt(buf EncodingBuffer, v *big.Int) {
+	var scratch [64]byte
+	buf.Write(v.Append(scratch[:0], 10))
+}
+
+// WriteBigFloat writes v as a JSON string, the same way encoding/json does
+// using (*big.Float).MarshalText.
+func WriteBigFloat(buf EncodingBuffer, v *big.Float) {
+	var scratch [64]byte
+	buf.WriteByte('"')
+	buf.Write(v.Append(scratch[:0], 'g', -1))
+	buf.WriteByte('"')
+}
+
+// WriteBigRat writes v as a JSON string, the same way encoding/json does
+// using (*big.Rat).MarshalText.
+func WriteBigRat(buf EncodingBuffer, v *big.Rat) {
+	buf.WriteByte('"')
+	buf.WriteString(v.RatString())
+	buf.WriteByte('"')
+}
+
+// ParseBigInt parses the bytes of an integer token into dst.
+func ParseBigInt(dst *big.Int, s []byte) error {
+	if _, ok := dst.SetString(string(s), 10); !ok {
+		return fmt.Errorf("ffjson: cannot unmarshal %q into a *big.Int", s)
+	}
+	return nil
+}
+
+// ParseBigFloat parses the bytes of a number token, or of a string
+// holding a number, into dst. If dst has no precision yet it gets
+// the same default precision as (*big.Float).UnmarshalText would give it.
+func ParseBigFloat(dst *big.Float, s []byte) error {
+	return dst.UnmarshalText(s)
+}
+
+// ParseBigRat parses the bytes of a number token, or of a string holding
+// a number or a fraction such as "1/3", into dst.
+func ParseBigRat(dst *big.Rat, s []byte) error {
+	if _, ok := dst.SetString(string(s)); !ok {
+		return fmt.Errorf("ffjson: cannot unmarshal %q into a *big.Rat", s)
+	}
+	return nil
+}
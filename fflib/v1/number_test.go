@@ -0,0 +1,66 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIsValidNumber(t *testing.T) {
+	valid := []string{"0", "-0", "1", "-1", "0.1", "1e10", "1E+10", "-1.5e-7", "12345678901234567890123"}
+	invalid := []string{"", "-", "01", "1.", ".1", "1e", "1e+", "+1", "0x10", "1.5.5", "NaN", " 1"}
+
+	for _, v := range valid {
+		if !IsValidNumber([]byte(v)) {
+			t.Fatalf("expected %q to be a valid number", v)
+		}
+	}
+
+	for _, v := range invalid {
+		if IsValidNumber([]byte(v)) {
+			t.Fatalf("expected %q to be an invalid number", v)
+		}
+	}
+}
+
+func TestWriteBigNumbers(t *testing.T) {
+	var buf Buffer
+	i, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	WriteBigInt(&buf, i)
+	if buf.String() != "-123456789012345678901234567890" {
+		t.Fatalf("unexpected big.Int output: %s", buf.String())
+	}
+
+	buf.Reset()
+	WriteBigRat(&buf, big.NewRat(2, 6))
+	if buf.String() != `"1/3"` {
+		t.Fatalf("unexpected big.Rat output: %s", buf.String())
+	}
+
+	buf.Reset()
+	err := WriteJsonNumber(&buf, "")
+	if err != nil || buf.String() != "0" {
+		t.Fatalf("unexpected empty json.Number output: %s %v", buf.String(), err)
+	}
+
+	err = WriteJsonNumber(&buf, "1,5")
+	if err == nil {
+		t.Fatalf("expected error for invalid json.Number")
+	}
+}
@@ -0,0 +1,194 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+)
+
+// PatchOp is an RFC 6902 JSON Patch operation, as returned by generated
+// DiffJSON methods.
+type PatchOp struct {
+	// Op is "add", "remove" or "replace".
+	Op string
+	// Path is the RFC 6901 JSON Pointer of the value.
+	Path string
+	// Value is the new value for "add" and "replace". It is kept as a Go
+	// value, and only encoded by WritePatch.
+	Value interface{}
+}
+
+// QuotedValue is a PatchOp.Value that is encoded as a JSON string holding
+// the JSON of V, like fields with the ",string" option are.
+type QuotedValue struct {
+	V interface{}
+}
+
+// EncoderValue is a PatchOp.Value that is written by calling it. Generated
+// code uses it for fields with a codec, so they are written like
+// MarshalJSON writes them.
+type EncoderValue func(buf EncodingBuffer) error
+
+// DiffOp returns the operation for a value that changed: "add" if it was
+// absent, "remove" if it is absent now, and "replace" otherwise. Values are
+// absent when they are empty and omitted with omitempty.
+func DiffOp(wasAbsent, isAbsent bool) string {
+	switch {
+	case isAbsent:
+		return "remove"
+	case wasAbsent:
+		return "add"
+	}
+	return "replace"
+}
+
+// MarshalPatch returns ops as a JSON Patch document.
+func MarshalPatch(ops []PatchOp) ([]byte, error) {
+	var buf Buffer
+	err := WritePatch(&buf, ops)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WritePatch writes ops to buf as a JSON Patch document. Values of basic
+// types and of types with generated code are written directly, others are
+// encoded with encoding/json.
+func WritePatch(buf EncodingBuffer, ops []PatchOp) error {
+	buf.WriteByte('[')
+	for i := range ops {
+		op := &ops[i]
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`{"op":`)
+		WriteJsonString(buf, op.Op)
+		buf.WriteString(`,"path":`)
+		WriteJsonString(buf, op.Path)
+		if op.Op != "remove" {
+			buf.WriteString(`,"value":`)
+			err := writePatchValue(buf, op.Value)
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+type marshalerFaster interface {
+	MarshalJSONBuf(buf EncodingBuffer) error
+}
+
+var (
+	marshalerType     = reflect.TypeOf(new(json.Marshaler)).Elem()
+	textMarshalerType = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
+)
+
+func writePatchValue(buf EncodingBuffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+		return nil
+	case string:
+		WriteJsonString(buf, x)
+		return nil
+	case bool:
+		writeBool(buf, x)
+		return nil
+	case int:
+		FormatBits2(buf, uint64(x), 10, x < 0)
+		return nil
+	case int64:
+		FormatBits2(buf, uint64(x), 10, x < 0)
+		return nil
+	case uint64:
+		FormatBits2(buf, x, 10, false)
+		return nil
+	case float64:
+		return WriteJsonFloat(buf, x, 64)
+	case QuotedValue:
+		return writeQuotedValue(buf, x.V)
+	case EncoderValue:
+		return x(buf)
+	case marshalerFaster:
+		return x.MarshalJSONBuf(buf)
+	}
+
+	rv := reflect.ValueOf(v)
+	if c := LookupCodec(rv.Type()); c != nil {
+		return c.EncodeValue(buf, v)
+	}
+	if rv.Type().Implements(marshalerType) || rv.Type().Implements(textMarshalerType) {
+		return buf.Encode(v)
+	}
+
+	// Named types, and pointers to basic types.
+	switch rv.Kind() {
+	case reflect.String:
+		WriteJsonString(buf, rv.String())
+	case reflect.Bool:
+		writeBool(buf, rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		FormatBits2(buf, uint64(n), 10, n < 0)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		FormatBits2(buf, rv.Uint(), 10, false)
+	case reflect.Float32:
+		return WriteJsonFloat(buf, rv.Float(), 32)
+	case reflect.Float64:
+		return WriteJsonFloat(buf, rv.Float(), 64)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return writePatchValue(buf, rv.Elem().Interface())
+	default:
+		return buf.Encode(v)
+	}
+	return nil
+}
+
+func writeQuotedValue(buf EncodingBuffer, v interface{}) error {
+	if rv := reflect.ValueOf(v); v == nil || rv.Kind() == reflect.Ptr && rv.IsNil() {
+		// Like encoding/json, null stays unquoted.
+		buf.WriteString("null")
+		return nil
+	}
+	var tmp Buffer
+	err := writePatchValue(&tmp, v)
+	if err != nil {
+		return err
+	}
+	WriteJson(buf, tmp.Bytes())
+	return nil
+}
+
+func writeBool(buf EncodingBuffer, b bool) {
+	if b {
+		buf.WriteString("true")
+	} else {
+		buf.WriteString("false")
+	}
+}
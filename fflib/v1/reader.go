@@ -18,6 +18,8 @@
 package v1
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"unicode"
@@ -26,10 +28,28 @@ import (
 
 const sliceStringMask = cIJC | cNFP
 
+// defaultWindowSize is the initial size of the window used when
+// reading from an io.Reader. It grows when a single token is larger.
+const defaultWindowSize = 16 * 1024
+
 type ffReader struct {
 	s []byte
 	i int
 	l int
+
+	// When reading from an io.Reader, s is a window onto the stream:
+	// rd is refilled into window, and off is the stream offset of s[0].
+	// Bytes from mark on are kept when the window is refilled, so the
+	// current token stays contiguous.
+	rd     io.Reader
+	err    error
+	window []byte
+	off    int
+	mark   int
+	// lines and lineChar count what was dropped from the window,
+	// for PosWithLine.
+	lines    int
+	lineChar int
 }
 
 func newffReader(d []byte) *ffReader {
@@ -41,11 +61,13 @@ func newffReader(d []byte) *ffReader {
 }
 
 func (r *ffReader) Slice(start, stop int) []byte {
-	return r.s[start:stop]
+	return r.s[start-r.off : stop-r.off]
 }
 
+// Pos returns the offset in the input, counting from the start of the
+// stream when reading from an io.Reader.
 func (r *ffReader) Pos() int {
-	return r.i
+	return r.off + r.i
 }
 
 // Reset the reader, and add new input.
@@ -53,6 +75,80 @@ func (r *ffReader) Reset(d []byte) {
 	r.s = d
 	r.i = 0
 	r.l = len(d)
+	r.resetStream(nil)
+}
+
+// ResetReader resets the reader to read its input from rd, through a
+// window that is refilled as needed.
+func (r *ffReader) ResetReader(rd io.Reader) {
+	if r.window == nil {
+		r.window = make([]byte, defaultWindowSize)
+	}
+	r.s = r.window[:0]
+	r.i = 0
+	r.l = 0
+	r.resetStream(rd)
+}
+
+func (r *ffReader) resetStream(rd io.Reader) {
+	r.rd = rd
+	r.err = nil
+	r.off = 0
+	r.mark = 0
+	r.lines = 0
+	r.lineChar = 0
+}
+
+// fill reads more input from the io.Reader into the window. Bytes before
+// mark are dropped first, and fill returns how many, so the caller can
+// move any indexes it holds into s. It returns an error, usually io.EOF,
+// if there is no more input.
+func (r *ffReader) fill() (int, error) {
+	if r.rd == nil {
+		return 0, io.EOF
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	shift := r.mark
+	if shift > 0 {
+		dropped := r.s[:shift]
+		if n := bytes.Count(dropped, []byte{'\n'}); n > 0 {
+			r.lines += n
+			r.lineChar = len(dropped) - bytes.LastIndexByte(dropped, '\n') - 1
+		} else {
+			r.lineChar += len(dropped)
+		}
+		r.l = copy(r.window, r.s[shift:r.l])
+		r.i -= shift
+		r.off += shift
+		r.mark = 0
+	}
+
+	if r.l == len(r.window) {
+		// A single token fills the window.
+		window := make([]byte, 2*len(r.window))
+		copy(window, r.window[:r.l])
+		r.window = window
+	}
+
+	for tries := 0; tries < 100; tries++ {
+		n, err := r.rd.Read(r.window[r.l:])
+		r.l += n
+		r.s = r.window[:r.l]
+		if err != nil {
+			r.err = err
+		}
+		if n > 0 {
+			return shift, nil
+		}
+		if err != nil {
+			return shift, err
+		}
+	}
+	r.err = io.ErrNoProgress
+	return shift, r.err
 }
 
 // Calculates the Position with line and line offset,
@@ -60,8 +156,8 @@ func (r *ffReader) Reset(d []byte) {
 // it will iterate the buffer from the beginning, and should
 // only be used in error-paths.
 func (r *ffReader) PosWithLine() (int, int) {
-	currentLine := 1
-	currentChar := 0
+	currentLine := 1 + r.lines
+	currentChar := r.lineChar
 
 	for i := 0; i < r.i; i++ {
 		c := r.s[i]
@@ -76,44 +172,26 @@ func (r *ffReader) PosWithLine() (int, int) {
 }
 
 func (r *ffReader) ReadByteNoWS() (byte, error) {
-	if r.i >= r.l {
-		return 0, io.EOF
-	}
-
-	j := r.i
-
-	for {
-		c := r.s[j]
-		j++
-
-		// inline whitespace parsing gives another ~8% performance boost
-		// for many kinds of nicely indented JSON.
-		// ... and using a [255]bool instead of multiple ifs, gives another 2%
-		/*
-			if c != '\t' &&
-				c != '\n' &&
-				c != '\v' &&
-				c != '\f' &&
-				c != '\r' &&
-				c != ' ' {
-				r.i = j
-				return c, nil
-			}
-		*/
-		if whitespaceLookupTable[c] == false {
-			r.i = j
-			return c, nil
-		}
-
-		if j >= r.l {
-			return 0, io.EOF
+	j := skipWhitespace(r.s, r.i)
+	for j >= r.l {
+		// Only whitespace is left, none of it needs to be kept.
+		r.i = j
+		r.mark = j
+		if _, err := r.fill(); err != nil {
+			return 0, err
 		}
+		j = skipWhitespace(r.s, r.i)
 	}
+
+	r.i = j + 1
+	return r.s[j], nil
 }
 
 func (r *ffReader) ReadByte() (byte, error) {
 	if r.i >= r.l {
-		return 0, io.EOF
+		if _, err := r.fill(); err != nil {
+			return 0, err
+		}
 	}
 
 	r.i++
@@ -121,6 +199,12 @@ func (r *ffReader) ReadByte() (byte, error) {
 	return r.s[r.i-1], nil
 }
 
+// MarkLastByte records that the byte just read starts a token, the bytes
+// before it may be dropped when reading from an io.Reader.
+func (r *ffReader) MarkLastByte() {
+	r.mark = r.i - 1
+}
+
 func (r *ffReader) UnreadByte() error {
 	if r.i <= 0 {
 		panic("ffReader.UnreadByte: at beginning of slice")
@@ -227,30 +311,424 @@ func (r *ffReader) SliceString(out DecodingBuffer) error {
 
 	for {
 		if j >= r.l {
-			return io.EOF
+			// Hand what was scanned so far to out, so a long string
+			// does not have to fit in the window.
+			out.Write(r.s[r.i:j])
+			r.i = j
+			r.mark = j
+			if _, err := r.fill(); err != nil {
+				return err
+			}
+			j = r.i
 		}
 
 		j, c = scanString(r.s, j)
 
 		if c == '"' {
-			if j != r.i {
-				out.Write(r.s[r.i : j-1])
-				r.i = j
-			}
+			out.Write(r.s[r.i : j-1])
+			r.i = j
 			return nil
 		} else if c == '\\' {
+			if r.rd != nil && j+11 > r.l {
+				// The longest escape is \uXXXX\uXXXX, make sure it
+				// is in the window.
+				out.Write(r.s[r.i : j-1])
+				r.i = j - 1
+				r.mark = r.i
+				for r.l-r.i < 12 {
+					if _, err := r.fill(); err != nil {
+						break
+					}
+				}
+				j = r.i + 1
+			}
+
 			var err error
+			esc := j - 1
 			j, err = r.handleEscaped(c, j, out)
 			if err != nil {
+				r.i = esc
 				return err
 			}
+		} else if c == 0 && j == r.l && r.s[j-1] != 0 {
+			// Ran out of input inside the string.
+			continue
 		} else if byteLookupTable[c]&cIJC != 0 {
+			r.i = j - 1
 			return fmt.Errorf("lex_string_invalid_json_char: %v", c)
 		}
 		continue
 	}
 }
 
+// States of SkipContainer, telling what may come next in a container.
+const (
+	skipValueOrClose = iota // after '['
+	skipValue               // after ':', or ',' in an array
+	skipKeyOrClose          // after '{'
+	skipKey                 // after ',' in an object
+	skipColon               // after a key
+	skipCommaOrClose        // after a value
+)
+
+// SkipContainer moves past the rest of an object or array whose opening
+// '{' or '[' has already been read. Strings are not unescaped and numbers
+// are not converted, but the input is still checked to be valid JSON:
+// brackets must match, keys, values, commas and colons must alternate,
+// literals must be spelled right, numbers must follow the JSON grammar,
+// and strings must not have control characters or invalid escapes.
+func (r *ffReader) SkipContainer(open byte) error {
+	var stackBuf [64]byte
+	stack := append(stackBuf[:0], open)
+
+	state := skipValueOrClose
+	if open == '{' {
+		state = skipKeyOrClose
+	}
+
+	// The rest of a literal, or the state of a number, being skipped.
+	var literal string
+	number := numNone
+
+	var err error
+	j := r.i
+	for {
+		if j >= r.l {
+			if j, err = r.skipFill(j); err != nil {
+				return err
+			}
+		}
+
+		c := r.s[j]
+		j++
+
+		if literal != "" {
+			if c != literal[0] {
+				r.i = j - 1
+				return fmt.Errorf("ffjson: invalid character %q in literal", c)
+			}
+			literal = literal[1:]
+			continue
+		}
+
+		if number != numNone {
+			if next := skipNumber(number, c); next != numNone {
+				number = next
+				continue
+			}
+			if !numberComplete(number) {
+				r.i = j - 1
+				return fmt.Errorf("ffjson: invalid character %q in number", c)
+			}
+			number = numNone
+		}
+
+		if whitespaceLookupTable[c] {
+			j = skipWhitespace(r.s[:r.l], j)
+			continue
+		}
+
+		valueWanted := state == skipValue || state == skipValueOrClose
+		switch c {
+		case '"':
+			if !valueWanted && state != skipKey && state != skipKeyOrClose {
+				break
+			}
+			if j, err = r.skipString(j); err != nil {
+				return err
+			}
+			if valueWanted {
+				state = skipCommaOrClose
+			} else {
+				state = skipColon
+			}
+			continue
+
+		case '{', '[':
+			if !valueWanted {
+				break
+			}
+			stack = append(stack, c)
+			if c == '{' {
+				state = skipKeyOrClose
+			} else {
+				state = skipValueOrClose
+			}
+			continue
+
+		case '}', ']':
+			top := stack[len(stack)-1]
+			if top == '{' && c != '}' || top == '[' && c != ']' {
+				r.i = j - 1
+				return fmt.Errorf("ffjson: unexpected %q in %s", c, containerName(top))
+			}
+			if state != skipCommaOrClose && state != skipKeyOrClose && state != skipValueOrClose {
+				break
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				r.i = j
+				return nil
+			}
+			state = skipCommaOrClose
+			continue
+
+		case ',':
+			if state != skipCommaOrClose {
+				break
+			}
+			if stack[len(stack)-1] == '{' {
+				state = skipKey
+			} else {
+				state = skipValue
+			}
+			continue
+
+		case ':':
+			if state != skipColon {
+				break
+			}
+			state = skipValue
+			continue
+
+		case 't', 'f', 'n':
+			if !valueWanted {
+				break
+			}
+			switch c {
+			case 't':
+				literal = "rue"
+			case 'f':
+				literal = "alse"
+			default:
+				literal = "ull"
+			}
+			if j+len(literal) <= r.l && string(r.s[j:j+len(literal)]) == literal {
+				j += len(literal)
+				literal = ""
+			}
+			state = skipCommaOrClose
+			continue
+
+		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			if !valueWanted {
+				break
+			}
+			number = skipNumber(numStart, c)
+			for j < r.l {
+				next := skipNumber(number, r.s[j])
+				if next == numNone {
+					break
+				}
+				number = next
+				j++
+			}
+			state = skipCommaOrClose
+			continue
+
+		case '/':
+			// Comments, as accepted by the lexer.
+			if j, err = r.skipComment(j); err != nil {
+				return err
+			}
+			continue
+		}
+
+		r.i = j - 1
+		return fmt.Errorf("ffjson: invalid character %q in %s", c, containerName(stack[len(stack)-1]))
+	}
+}
+
+// skipString moves past the rest of a string whose opening quote is before j.
+func (r *ffReader) skipString(j int) (int, error) {
+	var err error
+	for {
+		if j >= r.l {
+			if j, err = r.skipFill(j); err != nil {
+				return j, err
+			}
+		}
+
+		var sc byte
+		j, sc = scanString(r.s, j)
+		if sc == '"' {
+			return j, nil
+		}
+		if sc == '\\' {
+			if j >= r.l {
+				if j, err = r.skipFill(j); err != nil {
+					return j, err
+				}
+			}
+			ec := r.s[j]
+			j++
+			if ec == 'u' {
+				for k := 0; k < 4; k++ {
+					if j >= r.l {
+						if j, err = r.skipFill(j); err != nil {
+							return j, err
+						}
+					}
+					if !isHex(r.s[j]) {
+						r.i = j
+						return j, fmt.Errorf("lex_string_invalid_unicode_escape: %v", r.s[j])
+					}
+					j++
+				}
+			} else if byteLookupTable[ec]&cVEC == 0 {
+				r.i = j - 1
+				return j, fmt.Errorf("lex_string_invalid_escaped_char: %v", ec)
+			}
+			continue
+		}
+		if sc == 0 && j == r.l && r.s[j-1] != 0 {
+			// Ran out of input inside the string.
+			continue
+		}
+		r.i = j - 1
+		return j, fmt.Errorf("lex_string_invalid_json_char: %v", sc)
+	}
+}
+
+// skipComment moves past a comment whose '/' is before j.
+func (r *ffReader) skipComment(j int) (int, error) {
+	var err error
+	if j >= r.l {
+		if j, err = r.skipFill(j); err != nil {
+			return j, err
+		}
+	}
+	switch r.s[j] {
+	case '/':
+		for {
+			if j >= r.l {
+				if j, err = r.skipFill(j); err != nil {
+					return j, err
+				}
+			}
+			if r.s[j] == '\n' {
+				return j, nil
+			}
+			j++
+		}
+	case '*':
+		j++
+		for star := false; ; j++ {
+			if j >= r.l {
+				if j, err = r.skipFill(j); err != nil {
+					return j, err
+				}
+			}
+			if star && r.s[j] == '/' {
+				return j + 1, nil
+			}
+			star = r.s[j] == '*'
+		}
+	}
+	r.i = j
+	return j, errors.New("ffjson: incomplete comment")
+}
+
+// States of a number skipped by SkipContainer, following the grammar of
+// RFC 8259: -?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?
+const (
+	numNone    = iota
+	numStart   // before the first byte
+	numMinus   // after '-'
+	numZero    // after a leading '0'
+	numInt     // in the integer digits
+	numDot     // after '.'
+	numFrac    // in the fraction digits
+	numE       // after 'e' or 'E'
+	numExpSign // after the sign of the exponent
+	numExp     // in the exponent digits
+)
+
+// skipNumber returns the state after c, or numNone if c cannot continue
+// a number in state st.
+func skipNumber(st int, c byte) int {
+	digit := c >= '0' && c <= '9'
+	switch st {
+	case numStart:
+		switch {
+		case c == '-':
+			return numMinus
+		case c == '0':
+			return numZero
+		case digit:
+			return numInt
+		}
+	case numMinus:
+		switch {
+		case c == '0':
+			return numZero
+		case digit:
+			return numInt
+		}
+	case numZero, numInt:
+		switch {
+		case digit && st == numInt:
+			return numInt
+		case c == '.':
+			return numDot
+		case c == 'e' || c == 'E':
+			return numE
+		}
+	case numDot, numFrac:
+		switch {
+		case digit:
+			return numFrac
+		case st == numFrac && (c == 'e' || c == 'E'):
+			return numE
+		}
+	case numE:
+		switch {
+		case c == '-' || c == '+':
+			return numExpSign
+		case digit:
+			return numExp
+		}
+	case numExpSign, numExp:
+		if digit {
+			return numExp
+		}
+	}
+	return numNone
+}
+
+func numberComplete(st int) bool {
+	return st == numZero || st == numInt || st == numFrac || st == numExp
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// skipFill refills the window for SkipContainer, which does not need
+// anything before j. It returns where j is in the refilled window, or
+// io.ErrUnexpectedEOF at the end of the input.
+func (r *ffReader) skipFill(j int) (int, error) {
+	r.i = j
+	r.mark = j
+	if _, err := r.fill(); err != nil {
+		return j, io.ErrUnexpectedEOF
+	}
+	return r.i, nil
+}
+
+func containerName(open byte) string {
+	if open == '{' {
+		return "object"
+	}
+	return "array"
+}
+
+// IsSpace reports whether c is JSON whitespace: space, tab, newline or
+// carriage return.
+func IsSpace(c byte) bool {
+	return whitespaceLookupTable[c]
+}
+
 // TODO(pquerna): consider combining wibth the normal byte mask.
 var whitespaceLookupTable [256]bool = [256]bool{
 	false, /* 0 */
@@ -264,8 +742,8 @@ var whitespaceLookupTable [256]bool = [256]bool{
 	false, /* 8 */
 	true,  /* 9 */
 	true,  /* 10 */
-	true,  /* 11 */
-	true,  /* 12 */
+	false, /* 11 */
+	false, /* 12 */
 	true,  /* 13 */
 	false, /* 14 */
 	false, /* 15 */
@@ -1,3 +1,6 @@
+//go:build ffjson_noswar
+// +build ffjson_noswar
+
 /**
  *  Copyright 2014 Paul Querna
  *
@@ -17,6 +20,10 @@
 
 package v1
 
+// The per-byte scanners. Build with the ffjson_noswar tag to use these
+// instead of the word-at-a-time ones in reader_scan_swar.go, e.g. to
+// compare the two.
+
 func scanString(s []byte, j int) (int, byte) {
 	for {
 		if j >= len(s) {
@@ -32,3 +39,10 @@ func scanString(s []byte, j int) (int, byte) {
 		return j, c
 	}
 }
+
+func skipWhitespace(s []byte, j int) int {
+	for j < len(s) && whitespaceLookupTable[s[j]] {
+		j++
+	}
+	return j
+}
@@ -0,0 +1,99 @@
+//go:build !ffjson_noswar
+// +build !ffjson_noswar
+
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+// Word-at-a-time ("SIMD within a register") scanners. Eight input bytes
+// are loaded into a uint64 and tested in parallel with a handful of
+// arithmetic and bitwise operations, in portable Go. The per-byte
+// versions in reader_scan_generic.go are used with the ffjson_noswar tag.
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	swarLo = 0x0101010101010101
+	swarHi = 0x8080808080808080
+	swar7F = 0x7f7f7f7f7f7f7f7f
+)
+
+// swarZero returns a word with the high bit set in exactly those bytes of v
+// that are zero. Unlike the classic (v - lo) & ^v & hi trick, it has no
+// false positives, because adding 0x7f to the low 7 bits never carries into
+// the next byte.
+func swarZero(v uint64) uint64 {
+	return ^((v&swar7F + swar7F) | v | swar7F)
+}
+
+// swarLess returns a word with the high bit set in exactly those bytes of v
+// that are less than n, for n <= 0x80.
+func swarLess(v uint64, n byte) uint64 {
+	// (b|0x80) - n never borrows from the next byte, and keeps the high bit
+	// set iff b&0x7f >= n. Bytes with the high bit set are never less than n.
+	return ^((v | swarHi) - swarLo*uint64(n)) & ^v & swarHi
+}
+
+// scanString returns the index after the first byte at or after j that ends
+// a run of plain string bytes: a quote, a backslash or a control character,
+// and that byte. If there is none it returns len(s) and 0.
+func scanString(s []byte, j int) (int, byte) {
+	for ; j+8 <= len(s); j += 8 {
+		v := binary.LittleEndian.Uint64(s[j:])
+		m := swarZero(v^(swarLo*'"')) | swarZero(v^(swarLo*'\\')) | swarLess(v, 0x20)
+		if m != 0 {
+			j += bits.TrailingZeros64(m) >> 3
+			return j + 1, s[j]
+		}
+	}
+
+	for ; j < len(s); j++ {
+		c := s[j]
+		if byteLookupTable[c]&sliceStringMask != 0 {
+			return j + 1, c
+		}
+	}
+	return j, 0
+}
+
+// skipWhitespace returns the index of the first byte at or after j that is
+// not whitespace, or len(s).
+func skipWhitespace(s []byte, j int) int {
+	// Most tokens are not preceded by whitespace at all,
+	// don't pay for a word load in that case.
+	if j < len(s) && !whitespaceLookupTable[s[j]] {
+		return j
+	}
+
+	for ; j+8 <= len(s); j += 8 {
+		v := binary.LittleEndian.Uint64(s[j:])
+		ws := swarZero(v^(swarLo*' ')) | swarZero(v^(swarLo*'\t')) |
+			swarZero(v^(swarLo*'\n')) | swarZero(v^(swarLo*'\r'))
+		if m := ^ws & swarHi; m != 0 {
+			return j + bits.TrailingZeros64(m)>>3
+		}
+	}
+
+	for j < len(s) && whitespaceLookupTable[s[j]] {
+		j++
+	}
+	return j
+}
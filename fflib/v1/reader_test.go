@@ -18,6 +18,8 @@
 package v1
 
 import (
+	"bytes"
+	"math/rand"
 	"testing"
 )
 
@@ -71,3 +73,88 @@ func TestInvalidEscape(t *testing.T) {
 		t.Fatalf("expected SliceString escape decode error")
 	}
 }
+
+// scanAlphabet mixes plain bytes with every byte class the scanners stop on
+// or skip over, including bytes with the high bit set.
+var scanAlphabet = []byte("aZ0 \t\n\v\f\r\"\\\x00\x1f\x7f\x80\x9c\xa0\xe2\xff")
+
+func TestScanString(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 10000; n++ {
+		s := make([]byte, r.Intn(40))
+		for i := range s {
+			if r.Intn(4) == 0 {
+				s[i] = scanAlphabet[r.Intn(len(scanAlphabet))]
+			} else {
+				s[i] = 'x'
+			}
+		}
+
+		for j := 0; j <= len(s); j++ {
+			want, wantc := len(s), byte(0)
+			for k := j; k < len(s); k++ {
+				if s[k] == '"' || s[k] == '\\' || s[k] < 0x20 {
+					want, wantc = k+1, s[k]
+					break
+				}
+			}
+
+			got, gotc := scanString(s, j)
+			if got != want || gotc != wantc {
+				t.Fatalf("scanString(%q, %d) = %d, %q; expected %d, %q", s, j, got, gotc, want, wantc)
+			}
+		}
+	}
+}
+
+func TestSkipWhitespace(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 10000; n++ {
+		s := make([]byte, r.Intn(40))
+		for i := range s {
+			if r.Intn(8) == 0 {
+				s[i] = scanAlphabet[r.Intn(len(scanAlphabet))]
+			} else {
+				s[i] = " \n\t"[r.Intn(3)]
+			}
+		}
+
+		for j := 0; j <= len(s); j++ {
+			want := j
+			for want < len(s) && bytes.IndexByte([]byte(" \t\n\r"), s[want]) >= 0 {
+				want++
+			}
+
+			got := skipWhitespace(s, j)
+			if got != want {
+				t.Fatalf("skipWhitespace(%q, %d) = %d; expected %d", s, j, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkSliceString(b *testing.B) {
+	in := []byte(`2015-02-17T16:04:05Z INFO request handled method=GET path=/api/v1/users/1234/profile status=200 duration=1.23ms user_agent=Mozilla/5.0 (X11; Linux x86_64)"`)
+	var out Buffer
+	ffr := newffReader(in)
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		out.Reset()
+		ffr.Reset(in)
+		if err := ffr.SliceString(&out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadByteNoWS(b *testing.B) {
+	in := []byte("\n                                \t\t1")
+	ffr := newffReader(in)
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		ffr.Reset(in)
+		if c, err := ffr.ReadByteNoWS(); c != '1' || err != nil {
+			b.Fatal(c, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// RedactedPlaceholder is written by generated MarshalJSONRedacted methods
+// instead of fields tagged ffjson:"redact".
+const RedactedPlaceholder = `"[REDACTED]"`
+
+// WriteMasked writes s as a JSON string with all but its last runes
+// replaced by '*', for fields tagged ffjson:"redact=mask". At most a quarter
+// of the runes, and at most 4, are kept.
+func WriteMasked(buf EncodingBuffer, s string) {
+	n := utf8.RuneCountInString(s)
+	keep := n / 4
+	if keep > 4 {
+		keep = 4
+	}
+
+	tail := len(s)
+	for i := 0; i < keep; i++ {
+		_, size := utf8.DecodeLastRuneInString(s[:tail])
+		tail -= size
+	}
+	WriteJsonString(buf, strings.Repeat("*", n-keep)+s[tail:])
+}
+
+var (
+	redactionMu  sync.Mutex
+	redactionKey []byte
+)
+
+// SetRedactionKey sets the key of the HMAC that WriteRedactedHash writes.
+// Hashes can only be correlated between processes that use the same key,
+// and only by those who know it. Until a key is set, or after an empty key
+// is set, a random key is used.
+func SetRedactionKey(key []byte) {
+	redactionMu.Lock()
+	defer redactionMu.Unlock()
+	redactionKey = append([]byte(nil), key...)
+}
+
+func getRedactionKey() []byte {
+	redactionMu.Lock()
+	defer redactionMu.Unlock()
+	if len(redactionKey) == 0 {
+		redactionKey = make([]byte, 32)
+		if _, err := rand.Read(redactionKey); err != nil {
+			panic("fflib.v1: no randomness for the redaction key: " + err.Error())
+		}
+	}
+	return redactionKey
+}
+
+// WriteRedactedHash writes the truncated HMAC-SHA256 of value as a JSON
+// string of the form "hmac-sha256:0123456789abcdef", for fields tagged
+// ffjson:"redact=hash". Generated code hashes the JSON encoding of the field,
+// so equal values can be correlated without being revealed. The key is set
+// with SetRedactionKey, without it low-entropy values like PINs could be
+// found by hashing every candidate.
+func WriteRedactedHash(buf EncodingBuffer, value []byte) {
+	mac := hmac.New(sha256.New, getRedactionKey())
+	mac.Write(value)
+	sum := mac.Sum(nil)
+
+	const prefix = `"hmac-sha256:`
+	var out [len(prefix) + 16 + 1]byte
+	copy(out[:], prefix)
+	for i, b := range sum[:8] {
+		out[len(prefix)+2*i] = hex[b>>4]
+		out[len(prefix)+2*i+1] = hex[b&0xF]
+	}
+	out[len(out)-1] = '"'
+	buf.Write(out[:])
+}
@@ -0,0 +1,64 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestWriteMasked(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"", `""`},
+		{"abc", `"***"`},
+		{"abcdefgh", `"******gh"`},
+		{"4111111111111111", `"************1111"`},
+		{"4111-1111-1111-1111-1111", `"********************1111"`},
+		{"\"ébé\"ébé", `"******bé"`},
+	}
+	for _, test := range tests {
+		var buf Buffer
+		WriteMasked(&buf, test.in)
+		if buf.String() != test.out {
+			t.Errorf("WriteMasked(%q): got %s, expected %s", test.in, buf.String(), test.out)
+		}
+	}
+}
+
+func TestWriteRedactedHash(t *testing.T) {
+	defer SetRedactionKey(nil)
+
+	var buf Buffer
+	WriteRedactedHash(&buf, []byte(`"secret"`))
+	random := buf.String()
+	if len(random) != len(`"hmac-sha256:0123456789abcdef"`) {
+		t.Errorf("WriteRedactedHash with a random key: got %s", random)
+	}
+
+	SetRedactionKey([]byte("key"))
+	buf.Reset()
+	WriteRedactedHash(&buf, []byte(`"secret"`))
+	// echo -n '"secret"' | openssl dgst -sha256 -hmac key
+	if buf.String() != `"hmac-sha256:4cb94b39b8c15ad9"` {
+		t.Errorf("WriteRedactedHash: got %s", buf.String())
+	}
+	if buf.String() == random {
+		t.Errorf("WriteRedactedHash: same hash with different keys")
+	}
+}
@@ -0,0 +1,68 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadCodecs reads the codec registry of -codecs. Each line maps a type to
+// the codec used for all its values, both with their full import path:
+//
+//	# type                                   codec
+//	github.com/shopspring/decimal.Decimal    example.com/app/codecs.Decimal
+//
+// Blank lines and lines starting with # are ignored.
+func ReadCodecs(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	codecs := make(map[string]string)
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !qualified(fields[0]) || !qualified(fields[1]) {
+			return nil, fmt.Errorf("%s:%d: expected \"path/to/pkg.Type path/to/pkg.Codec\", got %q", path, n, line)
+		}
+		if _, ok := codecs[fields[0]]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate codec for %s", path, n, fields[0])
+		}
+		codecs[fields[0]] = fields[1]
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return codecs, nil
+}
+
+// qualified returns whether name is of the form path/to/pkg.Name.
+func qualified(name string) bool {
+	i := strings.LastIndex(name, ".")
+	return i > 0 && i < len(name)-1 && !strings.HasSuffix(name[:i], "/")
+}
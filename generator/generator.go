@@ -23,14 +23,21 @@ import (
 	"os"
 )
 
-func GenerateFiles(goCmd string, inputPath string, outputPath string, importName string, forceRegenerate bool, resetFields bool) error {
+func GenerateFiles(goCmd string, inputPath string, outputPath string, importName string, forceRegenerate bool, resetFields bool, schema bool, tsPath string, codecsPath string) error {
 
 	if _, StatErr := os.Stat(outputPath); !os.IsNotExist(StatErr) {
 		inputFileInfo, inputFileErr := os.Stat(inputPath)
 		outputFileInfo, outputFileErr := os.Stat(outputPath)
 
 		if nil == outputFileErr && nil == inputFileErr {
-			if !forceRegenerate && inputFileInfo.ModTime().Before(outputFileInfo.ModTime()) {
+			upToDate := inputFileInfo.ModTime().Before(outputFileInfo.ModTime())
+			if upToDate && codecsPath != "" {
+				// A changed registry changes the generated code as well.
+				codecsFileInfo, codecsFileErr := os.Stat(codecsPath)
+				upToDate = nil == codecsFileErr && codecsFileInfo.ModTime().Before(outputFileInfo.ModTime())
+			}
+
+			if !forceRegenerate && upToDate {
 				fmt.Println("File " + outputPath + " already exists.")
 
 				return nil
@@ -43,7 +50,15 @@ func GenerateFiles(goCmd string, inputPath string, outputPath string, importName
 		return err
 	}
 
-	im := NewInceptionMain(goCmd, inputPath, outputPath, resetFields)
+	var codecs map[string]string
+	if codecsPath != "" {
+		codecs, err = ReadCodecs(codecsPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	im := NewInceptionMain(goCmd, inputPath, outputPath, resetFields, schema, tsPath, codecs)
 
 	err = im.Generate(packageName, structs, importName)
 	if err != nil {
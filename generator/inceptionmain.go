@@ -45,7 +45,7 @@ import (
 )
 
 func main() {
-	i := ffjsoninception.NewInception("{{.InputPath}}", "{{.PackageName}}", "{{.OutputPath}}", {{.ResetFields}})
+	i := ffjsoninception.NewInception("{{.InputPath}}", "{{.PackageName}}", "{{.OutputPath}}", {{.ResetFields}}, {{.Schema}}, {{printf "%q" .TypeScriptPath}}, {{printf "%#v" .Codecs}})
 	i.AddMany(importedinceptionpackage.FFJSONExpose())
 	i.Execute()
 }
@@ -66,24 +66,29 @@ import (
 func FFJSONExpose() []ffjsonshared.InceptionType {
 	rv := make([]ffjsonshared.InceptionType, 0)
 {{range .StructNames}}
-	rv = append(rv, ffjsonshared.InceptionType{Obj: {{.Name}}{}, Options: ffjson{{printf "%#v" .Options}} } )
+	rv = append(rv, ffjsonshared.InceptionType{Obj: {{.Name}}{}, Options: ffjson{{printf "%#v" .Options}}, Doc: {{printf "%q" .Doc}}, FieldDocs: {{printf "%#v" .FieldDocs}} } )
 {{end}}
 	return rv
 }
 `
 
 type structName struct {
-	Name    string
-	Options shared.StructOptions
+	Name      string
+	Options   shared.StructOptions
+	Doc       string
+	FieldDocs map[string]string
 }
 
 type templateCtx struct {
-	StructNames []structName
-	ImportName  string
-	PackageName string
-	InputPath   string
-	OutputPath  string
-	ResetFields bool
+	StructNames    []structName
+	ImportName     string
+	PackageName    string
+	InputPath      string
+	OutputPath     string
+	ResetFields    bool
+	Schema         bool
+	TypeScriptPath string
+	Codecs         map[string]string
 }
 
 type InceptionMain struct {
@@ -96,9 +101,12 @@ type InceptionMain struct {
 	tempMain     *os.File
 	tempExpose   *os.File
 	resetFields  bool
+	schema       bool
+	tsPath       string
+	codecs       map[string]string
 }
 
-func NewInceptionMain(goCmd string, inputPath string, outputPath string, resetFields bool) *InceptionMain {
+func NewInceptionMain(goCmd string, inputPath string, outputPath string, resetFields bool, schema bool, tsPath string, codecs map[string]string) *InceptionMain {
 	exposePath := getExposePath(inputPath)
 	return &InceptionMain{
 		goCmd:       goCmd,
@@ -106,6 +114,9 @@ func NewInceptionMain(goCmd string, inputPath string, outputPath string, resetFi
 		outputPath:  outputPath,
 		exposePath:  exposePath,
 		resetFields: resetFields,
+		schema:      schema,
+		tsPath:      tsPath,
+		codecs:      codecs,
 	}
 }
 
@@ -190,15 +201,20 @@ func (im *InceptionMain) Generate(packageName string, si []*StructInfo, importNa
 	for i, st := range si {
 		sn[i].Name = st.Name
 		sn[i].Options = st.Options
+		sn[i].Doc = st.Doc
+		sn[i].FieldDocs = st.FieldDocs
 	}
 
 	tc := &templateCtx{
-		ImportName:  importName,
-		PackageName: packageName,
-		StructNames: sn,
-		InputPath:   im.inputPath,
-		OutputPath:  im.outputPath,
-		ResetFields: im.resetFields,
+		ImportName:     importName,
+		PackageName:    packageName,
+		StructNames:    sn,
+		InputPath:      im.inputPath,
+		OutputPath:     im.outputPath,
+		ResetFields:    im.resetFields,
+		Schema:         im.schema,
+		TypeScriptPath: im.tsPath,
+		Codecs:         im.codecs,
 	}
 
 	t := template.Must(template.New("inception.go").Parse(inceptionMainTemplate))
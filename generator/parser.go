@@ -31,14 +31,17 @@ import (
 
 var noEncoder = flag.Bool("noencoder", false, "Do not generate encoder functions")
 var noDecoder = flag.Bool("nodecoder", false, "Do not generate decoder functions")
+var fieldSets = flag.Bool("fieldsets", false, "Generate MarshalJSONFields for sparse fieldsets")
 
 type StructField struct {
 	Name string
 }
 
 type StructInfo struct {
-	Name    string
-	Options shared.StructOptions
+	Name      string
+	Options   shared.StructOptions
+	Doc       string
+	FieldDocs map[string]string
 }
 
 func NewStructInfo(name string) *StructInfo {
@@ -47,6 +50,7 @@ func NewStructInfo(name string) *StructInfo {
 		Options: shared.StructOptions{
 			SkipDecoder: *noDecoder,
 			SkipEncoder: *noEncoder,
+			FieldSets:   *fieldSets,
 		},
 	}
 }
@@ -54,6 +58,7 @@ func NewStructInfo(name string) *StructInfo {
 var skipre = regexp.MustCompile("(.*)ffjson:(\\s*)((skip)|(ignore))(.*)")
 var skipdec = regexp.MustCompile("(.*)ffjson:(\\s*)((skipdecoder)|(nodecoder))(.*)")
 var skipenc = regexp.MustCompile("(.*)ffjson:(\\s*)((skipencoder)|(noencoder))(.*)")
+var fieldsets = regexp.MustCompile("(.*)ffjson:(\\s*)(fieldsets)(.*)")
 
 func shouldInclude(d *ast.Object) (bool, error) {
 	ts, ok := d.Decl.(*ast.TypeSpec)
@@ -119,6 +124,10 @@ func ExtractStructs(inputPath string) (string, []*StructInfo, error) {
 		if skipre.MatchString(t.Doc) {
 			delete(structs, t.Name)
 		} else {
+			if s, ok := structs[t.Name]; ok {
+				s.Doc = strings.TrimSpace(t.Doc)
+				s.FieldDocs = fieldDocs(t.Decl, t.Name)
+			}
 			if skipdec.MatchString(t.Doc) {
 				s, ok := structs[t.Name]
 				if ok {
@@ -131,6 +140,12 @@ func ExtractStructs(inputPath string) (string, []*StructInfo, error) {
 					s.Options.SkipEncoder = true
 				}
 			}
+			if fieldsets.MatchString(t.Doc) {
+				s, ok := structs[t.Name]
+				if ok {
+					s.Options.FieldSets = true
+				}
+			}
 		}
 	}
 
@@ -140,3 +155,51 @@ func ExtractStructs(inputPath string) (string, []*StructInfo, error) {
 	}
 	return packageName, rv, nil
 }
+
+// fieldDocs returns the doc comments of the fields of the struct name
+// declared by decl, by Go field name. Line comments are used for fields without a
+// doc comment.
+func fieldDocs(decl *ast.GenDecl, name string) map[string]string {
+	var docs map[string]string
+	for _, spec := range decl.Specs {
+		ts, ok := spec.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != name {
+			continue
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			continue
+		}
+		for _, f := range st.Fields.List {
+			text := strings.TrimSpace(f.Doc.Text())
+			if text == "" {
+				text = strings.TrimSpace(f.Comment.Text())
+			}
+			if text == "" {
+				continue
+			}
+
+			names := f.Names
+			if len(names) == 0 {
+				// Embedded field, named after its type.
+				typ := f.Type
+				if star, ok := typ.(*ast.StarExpr); ok {
+					typ = star.X
+				}
+				if sel, ok := typ.(*ast.SelectorExpr); ok {
+					typ = sel.Sel
+				}
+				if ident, ok := typ.(*ast.Ident); ok {
+					names = []*ast.Ident{ident}
+				}
+			}
+			for _, ident := range names {
+				if docs == nil {
+					docs = make(map[string]string)
+				}
+				docs[ident.Name] = text
+			}
+		}
+	}
+	return docs
+}
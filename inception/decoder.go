@@ -20,6 +20,7 @@ package ffjsoninception
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/pquerna/ffjson/shared"
@@ -60,6 +61,52 @@ func CreateUnmarshalJSON(ic *Inception, si *StructInfo) error {
 	return nil
 }
 
+// handleStructField returns the code decoding the field sf of a structure,
+// with its codec if it has one.
+func handleStructField(ic *Inception, name string, sf *StructField) string {
+	if sf.Codec != "" {
+		return tplStr(decodeTpl["handleCodec"], handleCodec{
+			IC:    ic,
+			Name:  name,
+			Typ:   sf.Typ,
+			Ptr:   sf.Pointer,
+			Codec: codecRef(ic, sf.Codec),
+		})
+	}
+	return handleField(ic, name, sf.Typ, sf.Pointer, sf.ForceString)
+}
+
+// codecRef returns the Go expression for codec, a codec variable named as
+// path/to/pkg.Name or as Name in the package of the structure. The package
+// is imported under an alias, as its name need not be the last element of
+// its path, like in gopkg.in/pkg.v1 or example.com/pkg/v2.
+func codecRef(ic *Inception, codec string) string {
+	i := strings.LastIndex(codec, ".")
+	if i < 0 || strings.LastIndex(codec, "/") > i {
+		return codec
+	}
+	pkgPath, name := codec[:i], codec[i+1:]
+	alias, ok := ic.codecAliases[pkgPath]
+	if !ok {
+		if ic.codecAliases == nil {
+			ic.codecAliases = make(map[string]string)
+		}
+		alias = "ffjcodec" + strconv.Itoa(len(ic.codecAliases))
+		ic.codecAliases[pkgPath] = alias
+		ic.OutputImports[alias+` "`+pkgPath+`"`] = true
+	}
+	return alias + "." + name
+}
+
+// typeCodec returns the codec registered for the named type typ with
+// -codecs, or "".
+func typeCodec(ic *Inception, typ reflect.Type) string {
+	if typ.Name() == "" || typ.PkgPath() == "" {
+		return ""
+	}
+	return ic.Codecs[typ.PkgPath()+"."+typ.Name()]
+}
+
 func handleField(ic *Inception, name string, typ reflect.Type, ptr bool, quoted bool) string {
 	return handleFieldAddr(ic, name, false, typ, ptr, quoted)
 }
@@ -67,6 +114,50 @@ func handleField(ic *Inception, name string, typ reflect.Type, ptr bool, quoted
 func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type, ptr bool, quoted bool) string {
 	out := fmt.Sprintf("/* handler: %s type=%v kind=%v quoted=%t*/\n", name, typ, typ.Kind(), quoted)
 
+	if codec := typeCodec(ic, typ); codec != "" {
+		out += tplStr(decodeTpl["handleCodec"], handleCodec{
+			IC:       ic,
+			Name:     name,
+			Typ:      typ,
+			Ptr:      ptr,
+			TakeAddr: takeAddr,
+			Codec:    codecRef(ic, codec),
+		})
+		return out
+	}
+
+	if typ.Kind() == reflect.Ptr && bigNumberName(typ.Elem()) != "" {
+		// *big.Int itself implements json.Unmarshaler, allocate it here.
+		out += tplStr(decodeTpl["handlePtr"], handlePtr{
+			IC:     ic,
+			Name:   name,
+			Typ:    typ,
+			Quoted: quoted,
+		})
+		return out
+	}
+
+	if bn := bigNumberName(typ); bn != "" {
+		// big.Int only takes integers, the others take anything strconv-like
+		// including the quoted form their MarshalText produces.
+		var allowed []string
+		if bn == "BigInt" {
+			allowed = buildTokens(quoted, "FFTok_string", "FFTok_integer", "FFTok_null")
+		} else {
+			allowed = []string{"FFTok_string", "FFTok_integer", "FFTok_double", "FFTok_null"}
+		}
+		out += getAllowTokens(typ.Name(), allowed...)
+
+		out += tplStr(decodeTpl["handleBigNumber"], handleBigNumber{
+			IC:       ic,
+			Name:     name,
+			Typ:      typ,
+			TakeAddr: takeAddr || ptr,
+			Func:     bn,
+		})
+		return out
+	}
+
 	umlx := typ.Implements(unmarshalFasterType) || typeInInception(ic, typ, shared.MustDecoder)
 	umlx = umlx || reflect.PtrTo(typ).Implements(unmarshalFasterType)
 
@@ -145,14 +236,16 @@ func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type
 
 	case reflect.String:
 		// Is it a json.Number?
-		if typ.PkgPath() == "encoding/json" && typ.Name() == "Number" {
-			// Fall back to json package to rely on the valid number check.
-			// See: https://github.com/golang/go/blob/f05c3aa24d815cd3869153750c9875e35fc48a6e/src/encoding/json/decode.go#L897
-			ic.OutputImports[`"encoding/json"`] = true
-			out += tplStr(decodeTpl["handleFallback"], handleFallback{
-				Name: name,
-				Typ:  typ,
-				Kind: typ.Kind(),
+		if isJsonNumber(typ) {
+			allowed := []string{"FFTok_string", "FFTok_integer", "FFTok_double", "FFTok_null"}
+			out += getAllowTokens(typ.Name(), allowed...)
+
+			out += tplStr(decodeTpl["handleJsonNumber"], handleString{
+				IC:       ic,
+				Name:     name,
+				Typ:      typ,
+				TakeAddr: takeAddr || ptr,
+				Quoted:   quoted,
 			})
 		} else {
 			out += tplStr(decodeTpl["handleString"], handleString{
@@ -164,7 +257,6 @@ func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type
 			})
 		}
 	case reflect.Interface:
-		ic.OutputImports[`"encoding/json"`] = true
 		out += tplStr(decodeTpl["handleFallback"], handleFallback{
 			Name: name,
 			Typ:  typ,
@@ -179,7 +271,6 @@ func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type
 			TakeAddr: takeAddr || ptr,
 		})
 	default:
-		ic.OutputImports[`"encoding/json"`] = true
 		out += tplStr(decodeTpl["handleFallback"], handleFallback{
 			Name: name,
 			Typ:  typ,
@@ -190,6 +281,65 @@ func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type
 	return out
 }
 
+// zeroField returns the statement setting the field name to its zero value,
+// for fields that are missing with -reset-fields and null in merge patches.
+func zeroField(ic *Inception, name string, sf *StructField) string {
+	typ := sf.Typ
+	switch {
+	case sf.Pointer:
+		return name + " = nil"
+	case typ.Kind() == reflect.Interface, typ.Kind() == reflect.Slice, typ.Kind() == reflect.Map:
+		return name + " = nil"
+	case typ.Kind() == reflect.Array:
+		return fmt.Sprintf("%s = [%d]%s{}", name, typ.Len(), getType(ic, name, typ.Elem()))
+	case typ.Kind() == reflect.Bool:
+		return name + " = false"
+	case typ.Kind() == reflect.String:
+		return name + ` = ""`
+	case typ.Kind() == reflect.Struct:
+		return fmt.Sprintf("%s = %s{}", name, getType(ic, name, typ))
+	}
+	return fmt.Sprintf("%s = %s(0)", name, getType(ic, name, typ))
+}
+
+// mergeField returns the code merging a JSON object into the field name when
+// the lexer has MergePatch set, for maps with string keys, where each key of
+// the patch is set or, if null, deleted. It returns "" for other fields.
+// Generated structs need nothing: they are decoded into the existing value,
+// which merges them recursively.
+func mergeField(ic *Inception, name string, sf *StructField) string {
+	typ := sf.Typ
+	switch {
+	case sf.Codec != "":
+		// The codec decodes the whole value.
+		return ""
+
+	case typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String && !sf.Pointer:
+		// Map values are decoded like struct fields: through a pointer
+		// if the value type is an unnamed pointer. Generated structs in
+		// the map are merged too.
+		elem, ptr := typ.Elem(), false
+		if elem.Name() == "" && elem.Kind() == reflect.Ptr {
+			elem, ptr = elem.Elem(), true
+		}
+		return tplStr(decodeTpl["mergeMap"], mergeMap{
+			IC:        ic,
+			Name:      name,
+			Typ:       typ,
+			Elem:      elem,
+			ElemPtr:   ptr,
+			MergeElem: elem.Kind() == reflect.Struct && mergeable(ic, elem),
+		})
+	}
+	return ""
+}
+
+// mergeable reports whether typ has a generated UnmarshalJSONFFLexer, in
+// this file or elsewhere, which merges when the lexer has MergePatch set.
+func mergeable(ic *Inception, typ reflect.Type) bool {
+	return typeInInception(ic, typ, shared.MustDecoder) || reflect.PtrTo(typ).Implements(unmarshalFasterType)
+}
+
 func getArrayHandler(ic *Inception, name string, typ reflect.Type, ptr bool) string {
 	if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
 		ic.OutputImports[`"encoding/base64"`] = true
@@ -215,8 +365,6 @@ func getArrayHandler(ic *Inception, name string, typ reflect.Type, ptr bool) str
 	if (typ.Elem().Kind() == reflect.Struct || typ.Elem().Kind() == reflect.Map) ||
 		typ.Elem().Kind() == reflect.Array || typ.Elem().Kind() == reflect.Slice &&
 		typ.Elem().Name() == "" {
-		ic.OutputImports[`"encoding/json"`] = true
-
 		return tplStr(decodeTpl["handleFallback"], handleFallback{
 			Name: name,
 			Typ:  typ,
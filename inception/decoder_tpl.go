@@ -19,7 +19,6 @@ package ffjsoninception
 
 import (
 	"reflect"
-	"strconv"
 	"text/template"
 )
 
@@ -33,6 +32,8 @@ func init() {
 		"allowTokens":       allowTokensTxt,
 		"handleFallback":    handleFallbackTxt,
 		"handleString":      handleStringTxt,
+		"handleJsonNumber":  handleJsonNumberTxt,
+		"handleBigNumber":   handleBigNumberTxt,
 		"handleObject":      handleObjectTxt,
 		"handleArray":       handleArrayTxt,
 		"handleSlice":       handleSliceTxt,
@@ -42,16 +43,21 @@ func init() {
 		"header":            headerTxt,
 		"ujFunc":            ujFuncTxt,
 		"handleUnmarshaler": handleUnmarshalerTxt,
+		"mergeMap":          mergeMapTxt,
+		"handleCodec":       handleCodecTxt,
 	}
 
 	tplFuncs := template.FuncMap{
-		"getAllowTokens":  getAllowTokens,
-		"getNumberSize":   getNumberSize,
-		"getType":         getType,
-		"handleField":     handleField,
-		"handleFieldAddr": handleFieldAddr,
-		"unquoteField":    unquoteField,
-		"getTmpVarFor":    getTmpVarFor,
+		"getAllowTokens":    getAllowTokens,
+		"getNumberSize":     getNumberSize,
+		"getType":           getType,
+		"handleField":       handleField,
+		"handleFieldAddr":   handleFieldAddr,
+		"handleStructField": handleStructField,
+		"unquoteField":      unquoteField,
+		"getTmpVarFor":      getTmpVarFor,
+		"zeroField":         zeroField,
+		"mergeField":        mergeField,
 	}
 
 	for k, v := range funcs {
@@ -122,7 +128,7 @@ var handleFallbackTxt = `
 		return fs.WrapErr(err)
 	}
 
-	err = json.Unmarshal(tbuf, &{{.Name}})
+	err = fs.UnmarshalFallback(tbuf, &{{.Name}})
 	if err != nil {
 		return fs.WrapErr(err)
 	}
@@ -162,6 +168,63 @@ var handleStringTxt = `
 }
 `
 
+var handleJsonNumberTxt = `
+{
+	{{$ic := .IC}}
+
+	if tok == fflib.FFTok_null {
+	{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+	{{end}}
+	} else {
+		outBuf := fs.Output.Bytes()
+		{{unquoteField .Quoted}}
+		// Number tokens are validated by the lexer, strings are not.
+		if tok == fflib.FFTok_string && !fflib.IsValidNumber(outBuf) {
+			return fs.WrapErr(fmt.Errorf("json: invalid number literal, trying to unmarshal %q into Number", outBuf))
+		}
+	{{if eq .TakeAddr true}}
+		tval := {{getType $ic .Name .Typ}}(string(outBuf))
+		{{.Name}} = &tval
+	{{else}}
+		{{.Name}} = {{getType $ic .Name .Typ}}(string(outBuf))
+	{{end}}
+	}
+}
+`
+
+type handleBigNumber struct {
+	IC       *Inception
+	Name     string
+	Typ      reflect.Type
+	TakeAddr bool
+	Func     string
+}
+
+var handleBigNumberTxt = `
+{
+	{{$ic := .IC}}
+
+	if tok == fflib.FFTok_null {
+	{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+	{{end}}
+	} else {
+	{{if eq .TakeAddr true}}
+		if {{.Name}} == nil {
+			{{.Name}} = new({{getType $ic .Name .Typ}})
+		}
+		err = fflib.Parse{{.Func}}({{.Name}}, fs.Output.Bytes())
+	{{else}}
+		err = fflib.Parse{{.Func}}(&{{.Name}}, fs.Output.Bytes())
+	{{end}}
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+	}
+}
+`
+
 type handleObject struct {
 	IC       *Inception
 	Name     string
@@ -518,6 +581,8 @@ var ffjKey{{$si.Name}}{{$field.Name}} = []byte({{$field.JsonName}})
 
 `
 
+// ujFunc renders UnmarshalJSONFFLexer, which also applies merge patches
+// for MergeJSONPatch.
 type ujFunc struct {
 	IC          *Inception
 	SI          *StructInfo
@@ -532,7 +597,22 @@ var ujFuncTxt = `
 // UnmarshalJSON umarshall json - template of ffjson
 func (j *{{.SI.Name}}) UnmarshalJSON(input []byte) error {
     fs := fflib.NewFFLexer(input)
-    return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+    err := j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+    if err != nil {
+        return err
+    }
+    return fs.ExpectEnd()
+}
+
+// MergeJSONPatch applies an RFC 7396 JSON merge patch - template ffjson
+func (j *{{.SI.Name}}) MergeJSONPatch(data []byte) error {
+    fs := fflib.NewFFLexer(data)
+    fs.MergePatch = true
+    err := j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+    if err != nil {
+        return err
+    }
+    return fs.ExpectEnd()
 }
 
 // UnmarshalJSONFFLexer fast json unmarshall - template ffjson
@@ -625,6 +705,17 @@ mainparse:
 			state = fflib.FFParse_want_value
 			continue
 		case fflib.FFParse_want_value:
+			if tok == fflib.FFTok_null && fs.MergePatch {
+				// null deletes the field.
+				switch currentKey {
+				{{range $index, $field := $si.Fields}}
+				case ffjt{{$si.Name}}{{$field.Name}}:
+					{{zeroField $ic ($field.Name | printf "j.%s") $field}}
+				{{end}}
+				}
+				state = fflib.FFParse_after_value
+				continue
+			}
 
 			if {{range $index, $v := .ValidValues}}{{if ne $index 0 }}||{{end}}tok == fflib.{{$v}}{{end}} {
 				switch currentKey {
@@ -648,7 +739,8 @@ mainparse:
 {{range $index, $field := $si.Fields}}
 handle_{{$field.Name}}:
 	{{with $fieldName := $field.Name | printf "j.%s"}}
-		{{handleField $ic $fieldName $field.Typ $field.Pointer $field.ForceString}}
+		{{mergeField $ic $fieldName $field}}
+		{{handleStructField $ic $fieldName $field}}
 		{{if eq $.ResetFields true}}
 		ffjSet{{$si.Name}}{{$field.Name}} = true
 		{{end}}
@@ -672,31 +764,13 @@ tokerror:
 	panic("ffjson-generated: unreachable, please report bug.")
 done:
 {{if eq .ResetFields true}}
+	if !fs.MergePatch {
 {{range $index, $field := $si.Fields}}
 	if !ffjSet{{$si.Name}}{{$field.Name}} {
-	{{with $fieldName := $field.Name | printf "j.%s"}}
-	{{if eq $field.Pointer true}}
-		{{$fieldName}} = nil
-	{{else if eq $field.Typ.Kind ` + strconv.FormatUint(uint64(reflect.Interface), 10) + `}}
-		{{$fieldName}} = nil
-	{{else if eq $field.Typ.Kind ` + strconv.FormatUint(uint64(reflect.Slice), 10) + `}}
-		{{$fieldName}} = nil
-	{{else if eq $field.Typ.Kind ` + strconv.FormatUint(uint64(reflect.Array), 10) + `}}
-		{{$fieldName}} = [{{$field.Typ.Len}}]{{getType $ic $fieldName $field.Typ.Elem}}{}
-	{{else if eq $field.Typ.Kind ` + strconv.FormatUint(uint64(reflect.Map), 10) + `}}
-		{{$fieldName}} = nil
-	{{else if eq $field.Typ.Kind ` + strconv.FormatUint(uint64(reflect.Bool), 10) + `}}
-		{{$fieldName}} = false
-	{{else if eq $field.Typ.Kind ` + strconv.FormatUint(uint64(reflect.String), 10) + `}}
-		{{$fieldName}} = ""
-	{{else if eq $field.Typ.Kind ` + strconv.FormatUint(uint64(reflect.Struct), 10) + `}}
-		{{$fieldName}} = {{getType $ic $fieldName $field.Typ}}{}
-	{{else}}
-		{{$fieldName}} = {{getType $ic $fieldName $field.Typ}}(0)
-	{{end}}
-	{{end}}
+		{{zeroField $ic ($field.Name | printf "j.%s") $field}}
 	}
 {{end}}
+	}
 {{end}}
 	return nil
 }
@@ -771,3 +845,110 @@ var handleUnmarshalerTxt = `
 	{{end}}
 	{{end}}
 `
+
+type handleCodec struct {
+	IC       *Inception
+	Name     string
+	Typ      reflect.Type
+	Ptr      bool
+	TakeAddr bool
+	Codec    string
+}
+
+var handleCodecTxt = `
+	{
+	{{if eq .Ptr true}}
+		if tok == fflib.FFTok_null {
+			{{.Name}} = nil
+		} else {
+			if {{.Name}} == nil {
+				{{.Name}} = new({{getType .IC .Typ.Name .Typ}})
+			}
+			err = {{.Codec}}.Decode(fs, tok, {{.Name}})
+		}
+	{{else if eq .TakeAddr true}}
+		err = {{.Codec}}.Decode(fs, tok, {{.Name}})
+	{{else}}
+		err = {{.Codec}}.Decode(fs, tok, &{{.Name}})
+	{{end}}
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		state = fflib.FFParse_after_value
+	}
+`
+
+type mergeMap struct {
+	IC        *Inception
+	Name      string
+	Typ       reflect.Type
+	Elem      reflect.Type
+	ElemPtr   bool
+	MergeElem bool
+}
+
+var mergeMapTxt = `
+	if tok == fflib.FFTok_left_bracket && fs.MergePatch {
+		{{$ic := .IC}}
+		{{$tmpVar := getTmpVarFor .Name}}
+		if {{.Name}} == nil {
+			{{.Name}} = make(map[{{getType $ic .Name .Typ.Key}}]{{if eq .ElemPtr true}}*{{end}}{{getType $ic .Name .Elem}}, 0)
+		}
+
+		for first := true; ; first = false {
+			tok = fs.Scan()
+			if tok == fflib.FFTok_error {
+				goto tokerror
+			}
+			if tok == fflib.FFTok_right_bracket {
+				break
+			}
+			if !first {
+				if tok != fflib.FFTok_comma {
+					wantedTok = fflib.FFTok_comma
+					goto wrongtokenerror
+				}
+				tok = fs.Scan()
+			}
+			if tok != fflib.FFTok_string {
+				wantedTok = fflib.FFTok_string
+				goto wrongtokenerror
+			}
+			k := {{getType $ic .Name .Typ.Key}}(fs.Output.String())
+
+			tok = fs.Scan()
+			if tok != fflib.FFTok_colon {
+				wantedTok = fflib.FFTok_colon
+				goto wrongtokenerror
+			}
+
+			tok = fs.Scan()
+			if tok == fflib.FFTok_null {
+				delete({{.Name}}, k)
+				continue
+			}
+			{{if eq .MergeElem true}}
+			if tok == fflib.FFTok_left_bracket {
+				{{$tmpVar}} := {{.Name}}[k]
+				{{if eq .ElemPtr true}}
+				if {{$tmpVar}} == nil {
+					{{$tmpVar}} = new({{getType $ic .Name .Elem}})
+				}
+				{{end}}
+				err = {{$tmpVar}}.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+				if err != nil {
+					return err
+				}
+				{{.Name}}[k] = {{$tmpVar}}
+				continue
+			}
+			{{end}}
+
+			var {{$tmpVar}} {{if eq .ElemPtr true}}*{{end}}{{getType $ic .Name .Elem}}
+			{{handleField .IC $tmpVar .Elem .ElemPtr false}}
+			{{.Name}}[k] = {{$tmpVar}}
+		}
+		state = fflib.FFParse_after_value
+		goto mainparse
+	}
+`
@@ -0,0 +1,198 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CreateDiffJSON generates DiffJSON and DiffJSONPath, which compare two
+// values field by field, and return the JSON Patch operations turning one
+// into the other. Fields are named and omitted as MarshalJSON does.
+func CreateDiffJSON(ic *Inception, si *StructInfo) error {
+	out := ""
+
+	out += "// DiffJSON returns the JSON Patch operations turning j into other - template\n"
+	out += `func (j *` + si.Name + `) DiffJSON(other *` + si.Name + `) []fflib.PatchOp {` + "\n"
+	out += `return j.DiffJSONPath(nil, "", other)` + "\n"
+	out += `}` + "\n"
+
+	out += "// DiffJSONPath appends the JSON Patch operations turning j into other to ops,\n"
+	out += "// with paths below prefix - template\n"
+	out += `func (j *` + si.Name + `) DiffJSONPath(ops []fflib.PatchOp, prefix string, other *` + si.Name + `) []fflib.PatchOp {` + "\n"
+	for _, f := range si.Fields {
+		out += diffField(ic, f)
+	}
+	out += `return ops` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+func diffField(ic *Inception, sf *StructField) string {
+	a := "j." + sf.Name
+	b := "other." + sf.Name
+	path := "prefix + " + strconv.Quote("/"+escapePointerSegment(jsonName(sf)))
+
+	value := b
+	if sf.ForceString && isDiffScalar(sf.Typ) {
+		value = "fflib.QuotedValue{V: " + b + "}"
+	}
+	appendOpWith := func(value string) string {
+		return "ops = append(ops, fflib.PatchOp{Op: fflib.DiffOp(" + absent(sf, a) + ", " + absent(sf, b) + "), " +
+			"Path: " + path + ", Value: " + value + "})\n"
+	}
+	appendOp := appendOpWith(value)
+
+	out := ""
+	switch {
+	case sf.Codec != "" || hasTypeCodec(ic, sf.Typ):
+		// Written like MarshalJSON writes it, with the codecs.
+		ic.OutputImports[`"reflect"`] = true
+		out += "if !reflect.DeepEqual(" + a + ", " + b + ") {\n"
+		out += "var value interface{}\n"
+		v := b
+		if sf.Pointer {
+			out += "if " + b + " != nil {\n"
+			v = "*" + b
+		}
+		out += "v := " + v + "\n"
+		out += "value = fflib.EncoderValue(func(buf fflib.EncodingBuffer) error {\n"
+		if sf.Codec != "" {
+			out += "return " + codecRef(ic, sf.Codec) + ".Encode(buf, v)\n"
+		} else {
+			out += "var err error\n"
+			out += "var obj []byte\n"
+			out += "_ = obj\n"
+			out += "_ = err\n"
+			out += getGetInnerValue(ic, "v", sf.Typ, false, false)
+			out += ic.q.Flush()
+			out += "return nil\n"
+		}
+		out += "})\n"
+		if sf.Pointer {
+			out += "}\n"
+		}
+		out += appendOpWith("value")
+		out += "}\n"
+
+	case sf.Typ.Kind() == reflect.Struct && diffable(ic, sf.Typ) && sf.Pointer:
+		out += "if " + a + " != nil && " + b + " != nil {\n"
+		out += "ops = " + a + ".DiffJSONPath(ops, " + path + ", " + b + ")\n"
+		out += "} else if " + a + " != " + b + " {\n"
+		out += appendOp
+		out += "}\n"
+
+	case sf.Typ.Kind() == reflect.Struct && diffable(ic, sf.Typ):
+		out += "ops = " + a + ".DiffJSONPath(ops, " + path + ", &" + b + ")\n"
+
+	case isDiffScalar(sf.Typ) && sf.Pointer:
+		out += "if (" + a + " == nil) != (" + b + " == nil) || " + a + " != nil && *" + a + " != *" + b + " {\n"
+		out += appendOp
+		out += "}\n"
+
+	case isDiffScalar(sf.Typ):
+		out += "if " + a + " != " + b + " {\n"
+		out += appendOp
+		out += "}\n"
+
+	default:
+		ic.OutputImports[`"reflect"`] = true
+		out += "if !reflect.DeepEqual(" + a + ", " + b + ") {\n"
+		out += appendOp
+		out += "}\n"
+	}
+	return out
+}
+
+// hasTypeCodec reports whether values of typ, or their elements, are
+// written with a codec of the -codecs registry.
+func hasTypeCodec(ic *Inception, typ reflect.Type) bool {
+	if typeCodec(ic, typ) != "" {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasTypeCodec(ic, typ.Elem())
+	}
+	return false
+}
+
+// isDiffScalar reports whether values of typ can be compared with ==.
+func isDiffScalar(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// absent returns the expression telling whether the field name is left
+// out of the JSON, which only omitempty fields are when empty.
+func absent(sf *StructField, name string) string {
+	if !sf.OmitEmpty {
+		return "false"
+	}
+	if sf.Pointer {
+		return name + " == nil"
+	}
+	switch sf.Typ.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return "len(" + name + ") == 0"
+	case reflect.Bool:
+		return "!" + name
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return name + " == 0"
+	case reflect.Interface, reflect.Ptr:
+		return name + " == nil"
+	}
+	return "false"
+}
+
+// diffable reports whether typ gets a generated DiffJSONPath.
+func diffable(ic *Inception, typ reflect.Type) bool {
+	for _, si := range ic.objs {
+		if si.Typ == typ {
+			return ic.wantMarshal(si)
+		}
+	}
+	return false
+}
+
+// jsonName returns the unquoted JSON name of a field.
+func jsonName(sf *StructField) string {
+	var name string
+	if err := json.Unmarshal([]byte(sf.JsonName), &name); err != nil {
+		panic(err)
+	}
+	return name
+}
+
+// escapePointerSegment escapes '~' and '/' for a JSON Pointer, RFC 6901.
+func escapePointerSegment(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
@@ -20,7 +20,10 @@ package ffjsoninception
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
+	fflib "github.com/pquerna/ffjson/fflib/v1"
 	"github.com/pquerna/ffjson/shared"
 )
 
@@ -90,6 +93,16 @@ func getMapValue(ic *Inception, name string, typ reflect.Type, ptr bool, forceSt
 		return out
 	}
 
+	if ic.marshalCall != "" && generatesMarshal(ic, typ.Elem()) {
+		// Encode the values with the variant too.
+		return getMapLoop(ic, name, typ, forceString)
+	}
+
+	if elem := typ.Elem(); typeCodec(ic, elem) != "" ||
+		(elem.Kind() == reflect.Ptr && typeCodec(ic, elem.Elem()) != "") {
+		return getMapLoop(ic, name, typ, forceString)
+	}
+
 	var elemKind reflect.Kind
 	elemKind = typ.Elem().Kind()
 
@@ -100,24 +113,7 @@ func getMapValue(ic *Inception, name string, typ reflect.Type, ptr bool, forceSt
 		reflect.Float32,
 		reflect.Float64,
 		reflect.Bool:
-
-		ic.OutputImports[`fflib "github.com/pquerna/ffjson/fflib/v1"`] = true
-
-		out += "if " + name + " == nil  {" + "\n"
-		ic.q.Write("null")
-		out += ic.q.GetQueued()
-		ic.q.DeleteLast()
-		out += "} else {" + "\n"
-		out += ic.q.WriteFlush("{ ")
-		out += "  for key, value := range " + name + " {" + "\n"
-		out += "    fflib.WriteJsonString(buf, key)" + "\n"
-		out += "    buf.WriteString(`:`)" + "\n"
-		out += getGetInnerValue(ic, "value", typ.Elem(), false, forceString)
-		out += "    buf.WriteByte(',')" + "\n"
-		out += "  }" + "\n"
-		out += "buf.Rewind(1)" + "\n"
-		out += ic.q.WriteFlush("}")
-		out += "}" + "\n"
+		out += getMapLoop(ic, name, typ, forceString)
 
 	default:
 		out += ic.q.Flush()
@@ -130,6 +126,45 @@ func getMapValue(ic *Inception, name string, typ reflect.Type, ptr bool, forceSt
 	return out
 }
 
+// getMapLoop returns the code writing the map name with string keys,
+// encoding the values like struct fields.
+func getMapLoop(ic *Inception, name string, typ reflect.Type, forceString bool) string {
+	var out = ""
+
+	ic.OutputImports[`fflib "github.com/pquerna/ffjson/fflib/v1"`] = true
+
+	out += "if " + name + " == nil  {" + "\n"
+	ic.q.Write("null")
+	out += ic.q.GetQueued()
+	ic.q.DeleteLast()
+	out += "} else {" + "\n"
+	out += ic.q.WriteFlush("{ ")
+	out += "  for key, value := range " + name + " {" + "\n"
+	out += "    fflib.WriteJsonString(buf, string(key))" + "\n"
+	out += "    buf.WriteString(`:`)" + "\n"
+	out += getGetInnerValue(ic, "value", typ.Elem(), false, forceString)
+	out += "    buf.WriteByte(',')" + "\n"
+	out += "  }" + "\n"
+	out += "buf.Rewind(1)" + "\n"
+	out += ic.q.WriteFlush("}")
+	out += "}" + "\n"
+	return out
+}
+
+// generatesMarshal returns whether typ, or the type it points to, has the
+// variant of MarshalJSONBuf in ic.marshalType: if it is generated in this
+// file, or typ already has the method, like types generated in other files
+// or packages.
+func generatesMarshal(ic *Inception, typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if si := ic.structInfo(typ); si != nil {
+		return ic.wantMarshal(si)
+	}
+	return typ.Implements(ic.marshalType) || reflect.PtrTo(typ).Implements(ic.marshalType)
+}
+
 func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, forceString bool) string {
 	var out = ""
 
@@ -138,12 +173,41 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		out += ic.q.Flush()
 	}
 
+	if codec := typeCodec(ic, typ); codec != "" {
+		ptname := name
+		if ptr {
+			ptname = "*" + name
+		}
+		out += ic.q.Flush()
+		out += "err = " + codecRef(ic, codec) + ".Encode(buf, " + ptname + ")" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "  return err" + "\n"
+		out += "}" + "\n"
+		return out
+	}
+
+	if bn := bigNumberName(typ); bn != "" {
+		ic.OutputImports[`fflib "github.com/pquerna/ffjson/fflib/v1"`] = true
+		out += ic.q.Flush()
+		if ptr {
+			out += "fflib.Write" + bn + "(buf, " + name + ")" + "\n"
+		} else {
+			out += "fflib.Write" + bn + "(buf, &" + name + ")" + "\n"
+		}
+		return out
+	}
+
 	if typ.Implements(marshalerFasterType) ||
 		reflect.PtrTo(typ).Implements(marshalerFasterType) ||
 		typeInInception(ic, typ, shared.MustEncoder) ||
 		typ.Implements(marshalerType) ||
 		reflect.PtrTo(typ).Implements(marshalerType) {
 
+		call := "MarshalJSONBuf(buf)"
+		if ic.marshalCall != "" && generatesMarshal(ic, typ) {
+			call = ic.marshalCall
+		}
+
 		out += ic.q.Flush()
 		out += tplStr(encodeTpl["handleMarshaler"], handleMarshaler{
 			IC:             ic,
@@ -152,6 +216,7 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 			Ptr:            reflect.Ptr,
 			MarshalJSONBuf: typ.Implements(marshalerFasterType) || reflect.PtrTo(typ).Implements(marshalerFasterType) || typeInInception(ic, typ, shared.MustEncoder),
 			Marshaler:      typ.Implements(marshalerType) || reflect.PtrTo(typ).Implements(marshalerType),
+			Call:           call,
 		})
 		return out
 	}
@@ -177,12 +242,13 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		reflect.Uintptr:
 		ic.OutputImports[`fflib "github.com/pquerna/ffjson/fflib/v1"`] = true
 		out += "fflib.FormatBits2(buf, uint64(" + ptname + "), 10, false)" + "\n"
-	case reflect.Float32:
-		ic.OutputImports[`fflib "github.com/pquerna/ffjson/fflib/v1"`] = true
-		out += "fflib.AppendFloat(buf, float64(" + ptname + "), 'g', -1, 32)" + "\n"
-	case reflect.Float64:
+	case reflect.Float32,
+		reflect.Float64:
 		ic.OutputImports[`fflib "github.com/pquerna/ffjson/fflib/v1"`] = true
-		out += "fflib.AppendFloat(buf, float64(" + ptname + "), 'g', -1, 64)" + "\n"
+		out += "err = fflib.WriteJsonFloat(buf, float64(" + ptname + "), " + getNumberSize(typ) + ")" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "  return err" + "\n"
+		out += "}" + "\n"
 	case reflect.Array,
 		reflect.Slice:
 
@@ -226,14 +292,19 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		}
 	case reflect.String:
 		// Is it a json.Number?
-		if typ.PkgPath() == "encoding/json" && typ.Name() == "Number" {
-			// Fall back to json package to rely on the valid number check.
-			// See: https://github.com/golang/go/blob/92cd6e3af9f423ab4d8ac78f24e7fd81c31a8ce6/src/encoding/json/encode.go#L550
+		if isJsonNumber(typ) {
+			ic.OutputImports[`fflib "github.com/pquerna/ffjson/fflib/v1"`] = true
 			out += fmt.Sprintf("/* json.Number */\n")
-			out += "err = buf.Encode(" + name + ")" + "\n"
+			if forceString {
+				out += "buf.WriteByte('\"')" + "\n"
+			}
+			out += "err = fflib.WriteJsonNumber(buf, string(" + ptname + "))" + "\n"
 			out += "if err != nil {" + "\n"
 			out += "  return err" + "\n"
 			out += "}" + "\n"
+			if forceString {
+				out += "buf.WriteByte('\"')" + "\n"
+			}
 		} else {
 			ic.OutputImports[`fflib "github.com/pquerna/ffjson/fflib/v1"`] = true
 			if forceString {
@@ -253,7 +324,8 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		out += "if " + name + "!= nil {" + "\n"
 		switch typ.Elem().Kind() {
 		case reflect.Struct:
-			out += getGetInnerValue(ic, name, typ.Elem(), false, false)
+			elemPtr := bigNumberName(typ.Elem()) != "" || typeCodec(ic, typ.Elem()) != ""
+			out += getGetInnerValue(ic, name, typ.Elem(), elemPtr, false)
 		default:
 			out += getGetInnerValue(ic, "*"+name, typ.Elem(), false, false)
 		}
@@ -270,8 +342,9 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		out += ic.q.WriteFlush("false")
 		out += "}" + "\n"
 	case reflect.Interface:
-		out += fmt.Sprintf("/* Interface types must use runtime reflection. type=%v kind=%v */\n", typ, typ.Kind())
-		out += "err = buf.Encode(" + name + ")" + "\n"
+		out += fmt.Sprintf("/* Interface types must use runtime reflection, or a registered codec. type=%v kind=%v */\n", typ, typ.Kind())
+		ic.OutputImports[`fflib "github.com/pquerna/ffjson/fflib/v1"`] = true
+		out += "err = fflib.EncodeInterface(buf, " + name + ")" + "\n"
 		out += "if err != nil {" + "\n"
 		out += "  return err" + "\n"
 		out += "}" + "\n"
@@ -279,16 +352,25 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		out += getMapValue(ic, ptname, typ, ptr, forceString)
 	case reflect.Struct:
 		if typ.Name() == "" {
-			ic.q.Write("{")
-			ic.q.Write(" ")
 			out += fmt.Sprintf("/* Inline struct. type=%v kind=%v */\n", typ, typ.Kind())
 			newV := reflect.Indirect(reflect.New(typ)).Interface()
-			fields := extractFields(newV)
+			fields := redactedFields(ic, extractFields(newV))
 
-			// Output all fields
+			// Adjust field names
 			for _, field := range fields {
-				// Adjust field name
 				field.Name = name + "." + field.Name
+			}
+
+			if ic.viewing {
+				out += getConditionalFields(ic, fields, "", viewCond)
+				break
+			}
+
+			ic.q.Write("{")
+			ic.q.Write(" ")
+
+			// Output all fields
+			for _, field := range fields {
 				out += getField(ic, field, "")
 			}
 
@@ -324,6 +406,20 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 }
 
 func getValue(ic *Inception, sf *StructField, prefix string) string {
+	if sf.Codec != "" {
+		ptname := prefix + sf.Name
+		if sf.Pointer {
+			// getField handles nil.
+			ptname = "*" + ptname
+		}
+		out := ic.q.Flush()
+		out += "err = " + codecRef(ic, sf.Codec) + ".Encode(buf, " + ptname + ")" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "  return err" + "\n"
+		out += "}" + "\n"
+		return out
+	}
+
 	closequote := false
 	if sf.ForceString {
 		switch sf.Typ.Kind() {
@@ -452,7 +548,11 @@ func getField(ic *Inception, f *StructField, prefix string) string {
 	// We save a copy in case we need it
 	t := ic.q
 
-	out += getValue(ic, f, prefix)
+	if ic.redacting && f.Redact != "" {
+		out += getRedactedValue(ic, f, prefix)
+	} else {
+		out += getValue(ic, f, prefix)
+	}
 	ic.q.Write(",")
 
 	if f.Pointer && !f.OmitEmpty {
@@ -500,6 +600,16 @@ func CreateMarshalJSON(ic *Inception, si *StructInfo) error {
 	out += `return buf.Bytes(), nil` + "\n"
 	out += `}` + "\n"
 
+	out += "// AppendJSON appends the json of j to dst - template\n"
+	out += `func (j *` + si.Name + `) AppendJSON(dst []byte) ([]byte, error) {` + "\n"
+	out += `buf := fflib.AppendBuffer(dst)` + "\n"
+	out += `err := j.MarshalJSONBuf(&buf)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return dst, err" + "\n"
+	out += `}` + "\n"
+	out += `return buf, nil` + "\n"
+	out += `}` + "\n"
+
 	out += "// MarshalJSONBuf marshal buff to json - template\n"
 	out += `func (j *` + si.Name + `) MarshalJSONBuf(buf fflib.EncodingBuffer) (error) {` + "\n"
 	out += `  if j == nil {` + "\n"
@@ -539,6 +649,303 @@ func CreateMarshalJSON(ic *Inception, si *StructInfo) error {
 	out += ic.q.WriteFlush("}")
 	out += `return nil` + "\n"
 	out += `}` + "\n"
+
+	if si.Options.FieldSets {
+		out += createMarshalJSONFields(ic, si)
+	}
+	out += createMarshalJSONView(ic, si)
+
+	redacted, err := createMarshalJSONRedacted(ic, si)
+	if err != nil {
+		return err
+	}
+	out += redacted
 	ic.OutputFuncs = append(ic.OutputFuncs, out)
 	return nil
 }
+
+// createMarshalJSONFields generates MarshalJSONFields, which only writes the
+// fields in a fflib.FieldSet, and JSONFieldSet to build the set from JSON
+// names. Bit i of the set is the i-th field of si.Fields.
+func createMarshalJSONFields(ic *Inception, si *StructInfo) string {
+	out := ""
+
+	out += "// JSONFieldSet returns the FieldSet selecting the fields with the given json names - template\n"
+	out += `func (j *` + si.Name + `) JSONFieldSet(names ...string) fflib.FieldSet {` + "\n"
+	out += `var include fflib.FieldSet` + "\n"
+	if len(si.Fields) > 0 {
+		out += `for _, name := range names {` + "\n"
+		out += `switch name {` + "\n"
+		for i, f := range si.Fields {
+			out += `case ` + strconv.Quote(jsonName(f)) + `:` + "\n"
+			out += `include.Add(` + strconv.Itoa(i) + `)` + "\n"
+		}
+		out += `}` + "\n"
+		out += `}` + "\n"
+	}
+	out += `return include` + "\n"
+	out += `}` + "\n"
+
+	out += "// MarshalJSONFields marshal the fields in include to json - template\n"
+	out += `func (j *` + si.Name + `) MarshalJSONFields(buf fflib.EncodingBuffer, include fflib.FieldSet) (error) {` + "\n"
+	out += `  if j == nil {` + "\n"
+	out += `    buf.WriteString("null")` + "\n"
+	out += "    return nil" + "\n"
+	out += `  }` + "\n"
+
+	out += `var err error` + "\n"
+	out += `var obj []byte` + "\n"
+	out += `_ = obj` + "\n"
+	out += `_ = err` + "\n"
+
+	out += getConditionalFields(ic, si.Fields, "j.", func(i int, f *StructField) string {
+		return `include.Has(` + strconv.Itoa(i) + `)`
+	})
+	out += `return nil` + "\n"
+	out += `}` + "\n"
+	return out
+}
+
+// createMarshalJSONView generates MarshalJSONView, which only writes the
+// fields visible in a view, and uses MarshalJSONView for nested generated
+// types too. Without views it only calls MarshalJSONBuf.
+func createMarshalJSONView(ic *Inception, si *StructInfo) string {
+	out := ""
+
+	if !usesTag(ic, si.Fields, marshalerViewType, func(f *StructField) bool { return f.Views != nil }, nil) {
+		out += "// MarshalJSONView marshal buff to json, " + si.Name + " has no views - template\n"
+		out += `func (j *` + si.Name + `) MarshalJSONView(buf fflib.EncodingBuffer, view string) error { return j.MarshalJSONBuf(buf) }` + "\n"
+		return out
+	}
+
+	out += "// MarshalJSONView marshal the fields visible in view to json - template\n"
+	out += `func (j *` + si.Name + `) MarshalJSONView(buf fflib.EncodingBuffer, view string) (error) {` + "\n"
+	out += `  if j == nil {` + "\n"
+	out += `    buf.WriteString("null")` + "\n"
+	out += "    return nil" + "\n"
+	out += `  }` + "\n"
+
+	out += `var err error` + "\n"
+	out += `var obj []byte` + "\n"
+	out += `_ = obj` + "\n"
+	out += `_ = err` + "\n"
+
+	ic.marshalCall, ic.marshalType = "MarshalJSONView(buf, view)", marshalerViewType
+	ic.viewing = true
+	out += getConditionalFields(ic, si.Fields, "j.", viewCond)
+	ic.marshalCall, ic.marshalType = "", nil
+	ic.viewing = false
+
+	out += `return nil` + "\n"
+	out += `}` + "\n"
+	return out
+}
+
+// viewCond returns the condition for writing f in MarshalJSONView.
+func viewCond(i int, f *StructField) string {
+	if f.Views == nil {
+		return ""
+	}
+	cond := make([]string, len(f.Views))
+	for i, view := range f.Views {
+		cond[i] = `view == ` + strconv.Quote(view)
+	}
+	return strings.Join(cond, " || ")
+}
+
+// createMarshalJSONRedacted generates MarshalJSONRedacted, which writes the
+// fields tagged ffjson:"redact" in a form that does not reveal them, and
+// uses MarshalJSONRedacted for nested generated types too. Without redacted
+// fields it only calls MarshalJSONBuf.
+func createMarshalJSONRedacted(ic *Inception, si *StructInfo) (string, error) {
+	err := checkRedactModes(si.Name, si.Fields)
+	if err != nil {
+		return "", err
+	}
+
+	out := ""
+
+	if !usesTag(ic, si.Fields, marshalerRedactedType, func(f *StructField) bool { return f.Redact != "" }, nil) {
+		out += "// MarshalJSONRedacted marshal buff to json, " + si.Name + " has no redacted fields - template\n"
+		out += `func (j *` + si.Name + `) MarshalJSONRedacted(buf fflib.EncodingBuffer) error { return j.MarshalJSONBuf(buf) }` + "\n"
+		return out, nil
+	}
+
+	out += "// MarshalJSONRedacted marshal buff to json, with the redacted fields hidden - template\n"
+	out += `func (j *` + si.Name + `) MarshalJSONRedacted(buf fflib.EncodingBuffer) (error) {` + "\n"
+	out += `  if j == nil {` + "\n"
+	out += `    buf.WriteString("null")` + "\n"
+	out += "    return nil" + "\n"
+	out += `  }` + "\n"
+
+	out += `var err error` + "\n"
+	out += `var obj []byte` + "\n"
+	out += `_ = obj` + "\n"
+	out += `_ = err` + "\n"
+
+	ic.marshalCall, ic.marshalType = "MarshalJSONRedacted(buf)", marshalerRedactedType
+	ic.redacting = true
+	out += getConditionalFields(ic, redactedFields(ic, si.Fields), "j.", func(i int, f *StructField) string {
+		return ""
+	})
+	ic.marshalCall, ic.marshalType = "", nil
+	ic.redacting = false
+
+	out += `return nil` + "\n"
+	out += `}` + "\n"
+	return out, nil
+}
+
+// usesTag reports whether tagged is true for one of fields, or for a field
+// of a structure they contain, so the variant of MarshalJSONBuf in variant
+// must be generated instead of calling MarshalJSONBuf. Types generated in
+// other files or packages may use the tag if they have the variant.
+func usesTag(ic *Inception, fields []*StructField, variant reflect.Type, tagged func(f *StructField) bool, seen map[reflect.Type]bool) bool {
+	for _, f := range fields {
+		if tagged(f) {
+			return true
+		}
+		if f.Codec != "" {
+			continue
+		}
+
+		typ := f.Typ
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || typeCodec(ic, typ) != "" {
+			continue
+		}
+
+		var nested []*StructField
+		if typ.Name() == "" {
+			nested = extractFields(reflect.Indirect(reflect.New(typ)).Interface())
+		} else if si := ic.structInfo(typ); si != nil {
+			if seen[typ] || !ic.wantMarshal(si) {
+				continue
+			}
+			if seen == nil {
+				seen = make(map[reflect.Type]bool)
+			}
+			seen[typ] = true
+			nested = si.Fields
+		} else if reflect.PtrTo(typ).Implements(variant) {
+			return true
+		}
+		if usesTag(ic, nested, variant, tagged, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRedactModes returns an error for the first unknown ffjson redact mode
+// in fields, or in the fields of anonymous structures they contain, and for
+// redact=mask on values that are not strings.
+func checkRedactModes(name string, fields []*StructField) error {
+	for _, f := range fields {
+		switch f.Redact {
+		case "", "placeholder", "hash", "mask", "omit":
+		default:
+			return fmt.Errorf("%s.%s: unknown ffjson redact mode %q, expected hash, mask or omit", name, f.Name, f.Redact)
+		}
+		if f.Redact == "mask" && f.Typ.Kind() != reflect.String {
+			return fmt.Errorf("%s.%s: ffjson redact mode \"mask\" only applies to strings, not %v", name, f.Name, f.Typ)
+		}
+
+		typ := f.Typ
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+			typ = typ.Elem()
+		}
+		if typ.Kind() == reflect.Struct && typ.Name() == "" {
+			newV := reflect.Indirect(reflect.New(typ)).Interface()
+			err := checkRedactModes(name+"."+f.Name, extractFields(newV))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// redactedFields returns fields without those tagged ffjson:"redact=omit"
+// while generating MarshalJSONRedacted.
+func redactedFields(ic *Inception, fields []*StructField) []*StructField {
+	if !ic.redacting {
+		return fields
+	}
+	var rv []*StructField
+	for _, f := range fields {
+		if f.Redact != "omit" {
+			rv = append(rv, f)
+		}
+	}
+	return rv
+}
+
+// getRedactedValue returns the code writing the value of a field tagged
+// ffjson:"redact". Nil pointers have been handled by getField.
+func getRedactedValue(ic *Inception, sf *StructField, prefix string) string {
+	out := ""
+	ic.OutputImports[`fflib "github.com/pquerna/ffjson/fflib/v1"`] = true
+
+	ptname := prefix + sf.Name
+	if sf.Pointer {
+		ptname = "*" + ptname
+	}
+
+	switch {
+	case sf.Redact == "mask":
+		out += ic.q.Flush()
+		out += "fflib.WriteMasked(buf, string(" + ptname + "))" + "\n"
+
+	case sf.Redact == "hash":
+		// Hash the usual encoding of the value, written to a temporary
+		// buffer that shadows buf.
+		out += ic.q.Flush()
+		out += "{" + "\n"
+		out += "hashed := buf" + "\n"
+		out += "var tmp fflib.Buffer" + "\n"
+		out += "buf := fflib.EncodingBuffer(&tmp)" + "\n"
+		call, typ, redacting := ic.marshalCall, ic.marshalType, ic.redacting
+		ic.marshalCall, ic.marshalType, ic.redacting = "", nil, false
+		out += getValue(ic, sf, prefix)
+		ic.marshalCall, ic.marshalType, ic.redacting = call, typ, redacting
+		out += ic.q.Flush()
+		out += "fflib.WriteRedactedHash(hashed, tmp.Bytes())" + "\n"
+		out += "}" + "\n"
+
+	default:
+		ic.q.Write(fflib.RedactedPlaceholder)
+	}
+	return out
+}
+
+// getConditionalFields returns the code writing fields as a JSON object,
+// skipping every field for which cond returns an expression that is false.
+// Fields for which it returns "" are always written.
+func getConditionalFields(ic *Inception, fields []*StructField, prefix string, cond func(i int, f *StructField) string) string {
+	out := ""
+
+	// Any field can be skipped, so the space is always written, and the
+	// last comma or the space rewound at the end.
+	ic.q.Write("{")
+	ic.q.Write(" ")
+	out += ic.q.Flush()
+
+	for i, f := range fields {
+		c := cond(i, f)
+		if c != "" {
+			out += `if ` + c + ` {` + "\n"
+		}
+		out += getField(ic, f, prefix)
+		out += ic.q.Flush()
+		if c != "" {
+			out += `}` + "\n"
+		}
+	}
+
+	out += `buf.Rewind(1)` + "\n"
+	out += ic.q.WriteFlush("}")
+	return out
+}
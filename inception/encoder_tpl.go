@@ -44,6 +44,7 @@ type handleMarshaler struct {
 	Ptr            reflect.Kind
 	MarshalJSONBuf bool
 	Marshaler      bool
+	Call           string
 }
 
 var handleMarshalerTxt = `
@@ -55,7 +56,7 @@ var handleMarshalerTxt = `
 		{{end}}
 
 		{{if eq .MarshalJSONBuf true}}
-		err = {{.Name}}.MarshalJSONBuf(buf)
+		err = {{.Name}}.{{.Call}}
 		if err != nil {
 			return err
 		}
@@ -28,26 +28,47 @@ import (
 )
 
 type Inception struct {
-	objs          []*StructInfo
-	InputPath     string
-	OutputPath    string
-	PackageName   string
-	PackagePath   string
-	OutputImports map[string]bool
-	OutputFuncs   []string
-	q             ConditionalWrite
-	ResetFields   bool
+	objs           []*StructInfo
+	InputPath      string
+	OutputPath     string
+	PackageName    string
+	PackagePath    string
+	OutputImports  map[string]bool
+	OutputFuncs    []string
+	q              ConditionalWrite
+	ResetFields    bool
+	Schema         bool
+	TypeScriptPath string
+	// Codecs maps types, as path/to/pkg.Type, to the codec used for all
+	// their values, as path/to/pkg.Codec.
+	Codecs map[string]string
+
+	// marshalCall is the method called on nested generated types while
+	// generating a variant of MarshalJSONBuf, like MarshalJSONView, and
+	// marshalType the interface with that method.
+	marshalCall string
+	marshalType reflect.Type
+	// viewing is set while generating MarshalJSONView.
+	viewing bool
+	// redacting is set while generating MarshalJSONRedacted.
+	redacting bool
+	// codecAliases maps the import paths of codecs to the names they are
+	// imported as.
+	codecAliases map[string]string
 }
 
-func NewInception(inputPath string, packageName string, outputPath string, resetFields bool) *Inception {
+func NewInception(inputPath string, packageName string, outputPath string, resetFields bool, schema bool, tsPath string, codecs map[string]string) *Inception {
 	return &Inception{
-		objs:          make([]*StructInfo, 0),
-		InputPath:     inputPath,
-		OutputPath:    outputPath,
-		PackageName:   packageName,
-		OutputFuncs:   make([]string, 0),
-		OutputImports: make(map[string]bool),
-		ResetFields:   resetFields,
+		objs:           make([]*StructInfo, 0),
+		InputPath:      inputPath,
+		OutputPath:     outputPath,
+		PackageName:    packageName,
+		OutputFuncs:    make([]string, 0),
+		OutputImports:  make(map[string]bool),
+		ResetFields:    resetFields,
+		Schema:         schema,
+		TypeScriptPath: tsPath,
+		Codecs:         codecs,
 	}
 }
 
@@ -108,6 +129,11 @@ func (i *Inception) generateCode() error {
 			if err != nil {
 				return err
 			}
+
+			err = CreateDiffJSON(i, si)
+			if err != nil {
+				return err
+			}
 		}
 
 		if i.wantUnmarshal(si) {
@@ -157,4 +183,20 @@ func (i *Inception) Execute() {
 		return
 	}
 
+	if i.Schema {
+		err = i.writeSchemas(stat.Mode())
+		if err != nil {
+			i.handleError(err)
+			return
+		}
+	}
+
+	if i.TypeScriptPath != "" {
+		err = i.writeTypeScript(stat.Mode())
+		if err != nil {
+			i.handleError(err)
+			return
+		}
+	}
+
 }
@@ -23,7 +23,9 @@ import (
 
 	"bytes"
 	"encoding/json"
+	"math/big"
 	"reflect"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -38,6 +40,18 @@ type StructField struct {
 	HasUnmarshalJSON bool
 	Pointer          bool
 	Tagged           bool
+	// Views lists the views of MarshalJSONView the field is visible in,
+	// from the ffjson:"views=a|b" tag option. Fields without it are
+	// visible in every view.
+	Views []string
+	// Redact is how MarshalJSONRedacted writes the field: "placeholder"
+	// for the ffjson:"redact" tag option, or "hash", "mask" or "omit" for
+	// redact=mode. It is "" for fields that are written as usual.
+	Redact string
+	// Codec is the codec the field is encoded and decoded with, from the
+	// ffjson:"codec=path/to/pkg.Name" tag option, or just Name for a codec
+	// in the package of the structure. See codecRef.
+	Codec string
 }
 
 type FieldByJsonName []*StructField
@@ -47,21 +61,25 @@ func (a FieldByJsonName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a FieldByJsonName) Less(i, j int) bool { return a[i].JsonName < a[j].JsonName }
 
 type StructInfo struct {
-	Name    string
-	Obj     interface{}
-	Typ     reflect.Type
-	Fields  []*StructField
-	Options shared.StructOptions
+	Name      string
+	Obj       interface{}
+	Typ       reflect.Type
+	Fields    []*StructField
+	Options   shared.StructOptions
+	Doc       string
+	FieldDocs map[string]string
 }
 
 func NewStructInfo(obj shared.InceptionType) *StructInfo {
 	t := reflect.TypeOf(obj.Obj)
 	return &StructInfo{
-		Obj:     obj.Obj,
-		Name:    t.Name(),
-		Typ:     t,
-		Fields:  extractFields(obj.Obj),
-		Options: obj.Options,
+		Obj:       obj.Obj,
+		Name:      t.Name(),
+		Typ:       t,
+		Fields:    extractFields(obj.Obj),
+		Options:   obj.Options,
+		Doc:       obj.Doc,
+		FieldDocs: obj.FieldDocs,
 	}
 }
 
@@ -119,10 +137,42 @@ type UnmarshalFaster interface {
 	UnmarshalJSONFFLexer(l *fflib.FFLexer, state fflib.FFParseState) error
 }
 
+type MarshalerView interface {
+	MarshalJSONView(buf fflib.EncodingBuffer, view string) error
+}
+
+type MarshalerRedacted interface {
+	MarshalJSONRedacted(buf fflib.EncodingBuffer) error
+}
+
 var marshalerType = reflect.TypeOf(new(json.Marshaler)).Elem()
 var marshalerFasterType = reflect.TypeOf(new(MarshalerFaster)).Elem()
 var unmarshalerType = reflect.TypeOf(new(json.Unmarshaler)).Elem()
 var unmarshalFasterType = reflect.TypeOf(new(UnmarshalFaster)).Elem()
+var marshalerViewType = reflect.TypeOf(new(MarshalerView)).Elem()
+var marshalerRedactedType = reflect.TypeOf(new(MarshalerRedacted)).Elem()
+
+var bigIntType = reflect.TypeOf(big.Int{})
+var bigFloatType = reflect.TypeOf(big.Float{})
+var bigRatType = reflect.TypeOf(big.Rat{})
+
+// bigNumberName returns the suffix of the fflib Write/Parse functions
+// for a math/big type, or "" if typ is not one.
+func bigNumberName(typ reflect.Type) string {
+	switch typ {
+	case bigIntType:
+		return "BigInt"
+	case bigFloatType:
+		return "BigFloat"
+	case bigRatType:
+		return "BigRat"
+	}
+	return ""
+}
+
+func isJsonNumber(typ reflect.Type) bool {
+	return typ.PkgPath() == "encoding/json" && typ.Name() == "Number"
+}
 
 // extractFields returns a list of fields that JSON should recognize for the given type.
 // The algorithm is breadth-first search over the set of structs to include - the top struct
@@ -167,6 +217,7 @@ func extractFields(obj interface{}) []*StructField {
 				if !isValidTag(name) {
 					name = ""
 				}
+				ffopts := tagOptions(sf.Tag.Get("ffjson"))
 
 				ft := sf.Type
 				ptr := false
@@ -201,6 +252,17 @@ func extractFields(obj interface{}) []*StructField {
 						Pointer:          ptr,
 						Tagged:           tagged,
 					}
+					if views, ok := ffopts.Get("views"); ok {
+						field.Views = strings.Split(views, "|")
+					}
+					if mode, ok := ffopts.Get("redact"); ok {
+						field.Redact = mode
+					} else if ffopts.Contains("redact") {
+						field.Redact = "placeholder"
+					}
+					if codec, ok := ffopts.Get("codec"); ok {
+						field.Codec = codec
+					}
 
 					fields = append(fields, field)
 
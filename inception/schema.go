@@ -0,0 +1,360 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeOf(time.Time{})
+var textMarshalerType = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
+
+// schema is a JSON Schema object, which marshals its keywords in the order
+// they were set.
+type schema struct {
+	keys   []string
+	values []interface{}
+}
+
+func (s *schema) set(key string, value interface{}) *schema {
+	for i, k := range s.keys {
+		if k == key {
+			s.values[i] = value
+			return s
+		}
+	}
+	s.keys = append(s.keys, key)
+	s.values = append(s.values, value)
+	return s
+}
+
+func (s *schema) get(key string) interface{} {
+	for i, k := range s.keys {
+		if k == key {
+			return s.values[i]
+		}
+	}
+	return nil
+}
+
+func (s *schema) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	buf.WriteByte('{')
+	for i, k := range s.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(k); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		if err := enc.Encode(s.values[i]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func typeSchema(typ string) *schema {
+	return new(schema).set("type", typ)
+}
+
+// nullable returns s, also allowing null.
+func nullable(s *schema) *schema {
+	switch t := s.get("type").(type) {
+	case string:
+		s.set("type", []string{t, "null"})
+	case []string:
+		// Already nullable.
+	default:
+		if len(s.keys) == 0 {
+			// Anything goes.
+			return s
+		}
+		return new(schema).set("anyOf", []*schema{s, typeSchema("null")})
+	}
+	return s
+}
+
+// schemaGen builds the JSON Schema for a generated type, collecting the
+// named structures it refers to in $defs.
+type schemaGen struct {
+	ic    *Inception
+	defs  *schema
+	names map[reflect.Type]string
+	used  map[string]bool
+}
+
+// CreateSchema returns the JSON Schema document describing the JSON of si.
+func CreateSchema(ic *Inception, si *StructInfo) ([]byte, error) {
+	g := &schemaGen{
+		ic:    ic,
+		defs:  new(schema),
+		names: map[reflect.Type]string{si.Typ: ""},
+		used:  map[string]bool{},
+	}
+
+	s := new(schema)
+	s.set("$schema", schemaDialect)
+	s.set("title", si.Name)
+	if si.Doc != "" {
+		s.set("description", si.Doc)
+	}
+	root := g.valueSchema(si.Typ)
+	if _, ref := root.get("$ref").(string); ref {
+		root = g.objectSchema(si.Typ)
+	}
+	for i, k := range root.keys {
+		s.set(k, root.values[i])
+	}
+	if len(g.defs.keys) > 0 {
+		s.set("$defs", g.defs)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// schemaPath returns the path the schema for si is written to,
+// ${input}_${type}.schema.json.
+func schemaPath(ic *Inception, si *StructInfo) string {
+	return strings.TrimSuffix(ic.InputPath, ".go") + "_" + si.Name + ".schema.json"
+}
+
+func (i *Inception) writeSchemas(mode os.FileMode) error {
+	sorted := sortedStructs(i.objs)
+	sorted.Sort()
+
+	for _, si := range sorted {
+		data, err := CreateSchema(i, si)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(schemaPath(i, si), data, mode)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// structInfo returns the StructInfo of typ, or nil if it is not generated.
+func (i *Inception) structInfo(typ reflect.Type) *StructInfo {
+	for _, si := range i.objs {
+		if si.Typ == typ {
+			return si
+		}
+	}
+	return nil
+}
+
+// hasCustomMarshal returns whether typ is encoded with a MarshalJSON or
+// MarshalText method that is not generated, so its JSON is not known.
+func hasCustomMarshal(typ reflect.Type) bool {
+	ptr := reflect.PtrTo(typ)
+	if typ.Implements(marshalerFasterType) || ptr.Implements(marshalerFasterType) {
+		return false
+	}
+	return typ.Implements(marshalerType) || ptr.Implements(marshalerType)
+}
+
+func hasMarshalText(typ reflect.Type) bool {
+	return typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType)
+}
+
+// valueSchema returns the schema for the JSON encoding of a value of typ.
+func (g *schemaGen) valueSchema(typ reflect.Type) *schema {
+	if typ.Kind() == reflect.Ptr {
+		return nullable(g.valueSchema(typ.Elem()))
+	}
+	if typeCodec(g.ic, typ) != "" {
+		// Whatever the registered codec writes.
+		return new(schema)
+	}
+
+	switch {
+	case typ == timeType:
+		return typeSchema("string").set("format", "date-time")
+	case isJsonNumber(typ):
+		return typeSchema("number")
+	case bigNumberName(typ) == "BigInt":
+		return typeSchema("integer")
+	case bigNumberName(typ) != "":
+		return typeSchema("string")
+	case hasCustomMarshal(typ):
+		return new(schema)
+	case hasMarshalText(typ):
+		return typeSchema("string")
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return typeSchema("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return typeSchema("integer")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return typeSchema("integer").set("minimum", 0)
+	case reflect.Float32, reflect.Float64:
+		return typeSchema("number")
+	case reflect.String:
+		return typeSchema("string")
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 && !hasMarshalText(typ.Elem()) {
+			return nullable(typeSchema("string").set("contentEncoding", "base64"))
+		}
+		return nullable(typeSchema("array").set("items", g.valueSchema(typ.Elem())))
+	case reflect.Array:
+		return typeSchema("array").
+			set("items", g.valueSchema(typ.Elem())).
+			set("minItems", typ.Len()).
+			set("maxItems", typ.Len())
+	case reflect.Map:
+		return nullable(typeSchema("object").set("additionalProperties", g.valueSchema(typ.Elem())))
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return g.objectSchema(typ)
+		}
+		return g.ref(typ)
+	}
+
+	// Interfaces can hold anything.
+	return new(schema)
+}
+
+// ref returns a reference to the named structure typ, adding it to $defs
+// the first time. The root type is referenced as "#".
+func (g *schemaGen) ref(typ reflect.Type) *schema {
+	name, ok := g.names[typ]
+	if !ok {
+		name = typ.Name()
+		for n := 2; g.used[name]; n++ {
+			name = fmt.Sprintf("%s%d", typ.Name(), n)
+		}
+		g.used[name] = true
+		g.names[typ] = name
+
+		s := new(schema)
+		if si := g.ic.structInfo(typ); si != nil && si.Doc != "" {
+			s.set("description", si.Doc)
+		}
+		obj := g.objectSchema(typ)
+		for i, k := range obj.keys {
+			s.set(k, obj.values[i])
+		}
+		g.defs.set(name, s)
+	}
+
+	if name == "" {
+		return new(schema).set("$ref", "#")
+	}
+	return new(schema).set("$ref", "#/$defs/"+name)
+}
+
+// objectSchema returns the schema of the JSON object a structure of typ
+// encodes to, with the same fields as the generated code.
+func (g *schemaGen) objectSchema(typ reflect.Type) *schema {
+	props := new(schema)
+	required := []string{}
+	for _, sf := range extractFields(reflect.Zero(typ).Interface()) {
+		name := jsonName(sf)
+		props.set(name, g.fieldSchema(typ, sf))
+		if !sf.OmitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	s := typeSchema("object").set("properties", props)
+	if len(required) > 0 {
+		s.set("required", required)
+	}
+	return s
+}
+
+func (g *schemaGen) fieldSchema(typ reflect.Type, sf *StructField) *schema {
+	var s *schema
+	if sf.Codec != "" {
+		// Whatever the codec writes.
+		s = new(schema)
+	} else if sf.ForceString && isStringable(sf.Typ) {
+		s = typeSchema("string")
+	} else {
+		s = g.valueSchema(sf.Typ)
+	}
+	if sf.Pointer && sf.Typ.Kind() != reflect.Ptr {
+		// extractFields followed the unnamed pointer.
+		s = nullable(s)
+	}
+
+	// Siblings of $ref are allowed since draft 2019-09.
+	if doc := fieldDoc(g.ic, typ, sf); doc != "" {
+		s.set("description", doc)
+	}
+	return s
+}
+
+// isStringable returns whether the ",string" option applies to typ.
+func isStringable(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// fieldDoc returns the doc comment of sf, which may be promoted from a
+// structure embedded in typ.
+func fieldDoc(ic *Inception, typ reflect.Type, sf *StructField) string {
+	f, ok := typ.FieldByName(sf.Name)
+	if !ok {
+		return ""
+	}
+	owner := typ
+	for _, i := range f.Index[:len(f.Index)-1] {
+		owner = owner.Field(i).Type
+		if owner.Kind() == reflect.Ptr {
+			owner = owner.Elem()
+		}
+	}
+	if si := ic.structInfo(owner); si != nil {
+		return si.FieldDocs[sf.Name]
+	}
+	return ""
+}
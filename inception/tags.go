@@ -59,6 +59,24 @@ func (o tagOptions) Contains(optionName string) bool {
 	return false
 }
 
+// Get returns the value of an option of the form name=value, and whether
+// o contains it.
+func (o tagOptions) Get(optionName string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var next string
+		i := strings.Index(s, ",")
+		if i >= 0 {
+			s, next = s[:i], s[i+1:]
+		}
+		if strings.HasPrefix(s, optionName+"=") {
+			return s[len(optionName)+1:], true
+		}
+		s = next
+	}
+	return "", false
+}
+
 func isValidTag(s string) bool {
 	if s == "" {
 		return false
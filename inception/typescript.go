@@ -0,0 +1,228 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var tsIdentRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsGen builds TypeScript interfaces for the generated types, and for the
+// named structures they refer to.
+type tsGen struct {
+	ic    *Inception
+	names map[reflect.Type]string
+	used  map[string]bool
+	queue []reflect.Type
+}
+
+// CreateTypeScript returns TypeScript declarations with an interface
+// describing the JSON of every generated type.
+func CreateTypeScript(ic *Inception) ([]byte, error) {
+	g := &tsGen{
+		ic:    ic,
+		names: map[reflect.Type]string{},
+		used:  map[string]bool{},
+	}
+
+	sorted := sortedStructs(ic.objs)
+	sorted.Sort()
+	for _, si := range sorted {
+		g.name(si.Typ)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by ffjson <https://github.com/pquerna/ffjson>. DO NOT EDIT.\n")
+	buf.WriteString("// source: " + ic.InputPath + "\n")
+
+	// Referenced structures are queued while the others are written.
+	for i := 0; i < len(g.queue); i++ {
+		typ := g.queue[i]
+		buf.WriteString("\n")
+		if si := ic.structInfo(typ); si != nil {
+			writeTSDoc(&buf, "", si.Doc)
+		}
+		if hasCustomMarshal(typ) || hasMarshalText(typ) {
+			buf.WriteString("export type " + g.names[typ] + " = " + g.tsType(typ, "") + ";\n")
+			continue
+		}
+		buf.WriteString("export interface " + g.names[typ] + " " + g.tsObject(typ, "") + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+func (i *Inception) writeTypeScript(mode os.FileMode) error {
+	data, err := CreateTypeScript(i)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(i.TypeScriptPath, data, mode)
+}
+
+// name returns the TypeScript name of the named structure typ, queueing
+// its interface the first time.
+func (g *tsGen) name(typ reflect.Type) string {
+	name, ok := g.names[typ]
+	if !ok {
+		name = typ.Name()
+		for n := 2; g.used[name]; n++ {
+			name = fmt.Sprintf("%s%d", typ.Name(), n)
+		}
+		g.used[name] = true
+		g.names[typ] = name
+		g.queue = append(g.queue, typ)
+	}
+	return name
+}
+
+func tsNullable(t string) string {
+	if t == "unknown" || strings.HasSuffix(t, " | null") {
+		return t
+	}
+	return t + " | null"
+}
+
+func tsArray(elem string) string {
+	if strings.Contains(elem, " ") {
+		elem = "(" + elem + ")"
+	}
+	return elem + "[]"
+}
+
+// tsType returns the TypeScript type for the JSON encoding of a value of
+// typ. Inline object types are indented by indent.
+func (g *tsGen) tsType(typ reflect.Type, indent string) string {
+	if typ.Kind() == reflect.Ptr {
+		return tsNullable(g.tsType(typ.Elem(), indent))
+	}
+	if typeCodec(g.ic, typ) != "" {
+		// Whatever the registered codec writes.
+		return "unknown"
+	}
+
+	switch {
+	case typ == timeType:
+		return "string"
+	case isJsonNumber(typ):
+		return "number"
+	case bigNumberName(typ) == "BigInt":
+		return "number"
+	case bigNumberName(typ) != "":
+		return "string"
+	case hasCustomMarshal(typ):
+		return "unknown"
+	case hasMarshalText(typ):
+		return "string"
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 && !hasMarshalText(typ.Elem()) {
+			// base64
+			return "string | null"
+		}
+		return tsNullable(tsArray(g.tsType(typ.Elem(), indent)))
+	case reflect.Array:
+		return tsArray(g.tsType(typ.Elem(), indent))
+	case reflect.Map:
+		return tsNullable("Record<string, " + g.tsType(typ.Elem(), indent) + ">")
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return g.tsObject(typ, indent)
+		}
+		return g.name(typ)
+	}
+
+	// Interfaces can hold anything.
+	return "unknown"
+}
+
+// tsObject returns the TypeScript object type for a structure of typ, with
+// the same fields as the generated code.
+func (g *tsGen) tsObject(typ reflect.Type, indent string) string {
+	var buf bytes.Buffer
+	inner := indent + "  "
+
+	buf.WriteString("{\n")
+	for _, sf := range extractFields(reflect.Zero(typ).Interface()) {
+		writeTSDoc(&buf, inner, fieldDoc(g.ic, typ, sf))
+
+		name := jsonName(sf)
+		if !tsIdentRe.MatchString(name) {
+			name = sf.JsonName
+		}
+		if sf.OmitEmpty {
+			name += "?"
+		}
+
+		var t string
+		if sf.Codec != "" {
+			// Whatever the codec writes.
+			t = "unknown"
+		} else if sf.ForceString && isStringable(sf.Typ) {
+			t = "string"
+		} else {
+			t = g.tsType(sf.Typ, inner)
+		}
+		if sf.Pointer && sf.Typ.Kind() != reflect.Ptr {
+			// extractFields followed the unnamed pointer.
+			t = tsNullable(t)
+		}
+		if sf.OmitEmpty {
+			// nil is empty, and left out instead.
+			t = strings.TrimSuffix(t, " | null")
+		}
+
+		buf.WriteString(inner + name + ": " + t + ";\n")
+	}
+	buf.WriteString(indent + "}")
+	return buf.String()
+}
+
+// writeTSDoc writes doc as a JSDoc comment.
+func writeTSDoc(buf *bytes.Buffer, indent string, doc string) {
+	if doc == "" {
+		return
+	}
+	doc = strings.Replace(doc, "*/", "*\\/", -1)
+	lines := strings.Split(doc, "\n")
+	if len(lines) == 1 {
+		buf.WriteString(indent + "/** " + doc + " */\n")
+		return
+	}
+	buf.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		buf.WriteString(strings.TrimRight(indent+" * "+line, " ") + "\n")
+	}
+	buf.WriteString(indent + " */\n")
+}
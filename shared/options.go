@@ -20,11 +20,17 @@ package shared
 type StructOptions struct {
 	SkipDecoder bool
 	SkipEncoder bool
+	// FieldSets generates MarshalJSONFields and JSONFieldSet.
+	FieldSets bool
 }
 
 type InceptionType struct {
 	Obj     interface{}
 	Options StructOptions
+	// Doc is the doc comment of the type, and FieldDocs those of its
+	// fields by Go field name, as collected from the source.
+	Doc       string
+	FieldDocs map[string]string
 }
 type Feature int
 
@@ -0,0 +1,76 @@
+/**
+ *  Copyright 2016 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+// Package codec has a type without ffjson methods, and the codec for it
+// that the tests use in ffjson:"codec=..." tags, in -codecs registries and
+// with fflib.RegisterCodec.
+package codec
+
+import (
+	"errors"
+	"strconv"
+
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+)
+
+// Cents is an amount of money, written in JSON as a decimal string
+// like "12.34".
+type Cents int64
+
+// CentsCodec encodes and decodes Cents.
+var CentsCodec centsCodec
+
+type centsCodec struct{}
+
+func (centsCodec) Encode(buf fflib.EncodingBuffer, v Cents) error {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	frac := strconv.FormatInt(int64(v%100), 10)
+	if len(frac) == 1 {
+		frac = "0" + frac
+	}
+	buf.WriteString(`"` + sign + strconv.FormatInt(int64(v/100), 10) + "." + frac + `"`)
+	return nil
+}
+
+func (centsCodec) Decode(fs *fflib.FFLexer, tok fflib.FFTok, v *Cents) error {
+	if tok != fflib.FFTok_string && tok != fflib.FFTok_double && tok != fflib.FFTok_integer {
+		return errors.New("codec: Cents must be a decimal")
+	}
+	f, err := strconv.ParseFloat(fs.Output.String(), 64)
+	if err != nil {
+		return err
+	}
+	if f < 0 {
+		*v = Cents(f*100 - 0.5)
+	} else {
+		*v = Cents(f*100 + 0.5)
+	}
+	return nil
+}
+
+// EncodeValue and DecodeValue make CentsCodec an fflib.Codec.
+
+func (c centsCodec) EncodeValue(buf fflib.EncodingBuffer, v interface{}) error {
+	return c.Encode(buf, v.(Cents))
+}
+
+func (c centsCodec) DecodeValue(fs *fflib.FFLexer, tok fflib.FFTok, v interface{}) error {
+	return c.Decode(fs, tok, v.(*Cents))
+}
@@ -0,0 +1,54 @@
+/**
+ *  Copyright 2016 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+// Package codec is imported as .../codec/v2, so generated code must not
+// assume package names match the last element of import paths.
+package codec
+
+import (
+	"errors"
+	"strconv"
+
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"github.com/pquerna/ffjson/tests/codec"
+)
+
+// DollarsCodec encodes and decodes codec.Cents as a JSON number of dollars.
+var DollarsCodec dollarsCodec
+
+type dollarsCodec struct{}
+
+func (dollarsCodec) Encode(buf fflib.EncodingBuffer, v codec.Cents) error {
+	buf.WriteString(strconv.FormatFloat(float64(v)/100, 'f', -1, 64))
+	return nil
+}
+
+func (dollarsCodec) Decode(fs *fflib.FFLexer, tok fflib.FFTok, v *codec.Cents) error {
+	if tok != fflib.FFTok_double && tok != fflib.FFTok_integer {
+		return errors.New("codec: dollars must be a number")
+	}
+	f, err := strconv.ParseFloat(fs.Output.String(), 64)
+	if err != nil {
+		return err
+	}
+	if f < 0 {
+		*v = codec.Cents(f*100 - 0.5)
+	} else {
+		*v = codec.Cents(f*100 + 0.5)
+	}
+	return nil
+}
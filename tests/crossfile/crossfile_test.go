@@ -0,0 +1,54 @@
+/**
+ *  Copyright 2016 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package crossfile
+
+import (
+	"testing"
+
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	ff "github.com/pquerna/ffjson/tests/crossfile/ff"
+	"github.com/stretchr/testify/require"
+)
+
+func newOuter() *ff.Outer {
+	in := ff.Inner{Public: "p", Admin: "a", Secret: "s"}
+	return &ff.Outer{
+		In:     in,
+		PIn:    &in,
+		List:   []ff.Inner{in},
+		ByName: map[string]*ff.Inner{"x": &in},
+	}
+}
+
+func TestCrossFileView(t *testing.T) {
+	var buf fflib.Buffer
+	require.NoError(t, newOuter().MarshalJSONView(&buf, "public"))
+	require.Equal(t, `{ "in":{ "public":"p","secret":"s"},"pin":{ "public":"p","secret":"s"},"list":[{ "public":"p","secret":"s"}],`+
+		`"byName":{ "x":{ "public":"p","secret":"s"}}}`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, newOuter().MarshalJSONView(&buf, "admin"))
+	require.Contains(t, buf.String(), `"in":{ "public":"p","admin":"a","secret":"s"}`)
+}
+
+func TestCrossFileRedacted(t *testing.T) {
+	var buf fflib.Buffer
+	require.NoError(t, newOuter().MarshalJSONRedacted(&buf))
+	in := `{ "public":"p","admin":"a","secret":"[REDACTED]"}`
+	require.Equal(t, `{ "in":`+in+`,"pin":`+in+`,"list":[`+in+`],"byName":{ "x":`+in+`}}`, buf.String())
+}
@@ -21,6 +21,8 @@ import (
 	"errors"
 	"math"
 	"time"
+
+	"github.com/pquerna/ffjson/tests/codec"
 )
 
 // FFFoo struc... just  blah
@@ -2305,3 +2307,96 @@ type XDominantField struct {
 	Name  *int             `json",omitempty"`
 	A     *struct{ X int } `json:"Name,omitempty"`
 }
+
+// XMergeInner struct
+type XMergeInner struct {
+	A string `json:"a"`
+	B int    `json:"b,omitempty"`
+}
+
+// XMergePatch struct
+type XMergePatch struct {
+	Title  string                  `json:"title"`
+	Count  int                     `json:"count"`
+	Ptr    *int                    `json:"ptr"`
+	Inner  XMergeInner             `json:"inner"`
+	PInner *XMergeInner            `json:"pinner"`
+	Tags   []string                `json:"tags"`
+	Labels map[string]string       `json:"labels"`
+	Items  map[string]*XMergeInner `json:"items"`
+}
+
+// XDiff struct
+// ffjson: fieldsets
+type XDiff struct {
+	Name   string       `json:"name"`
+	Opt    string       `json:"opt,omitempty"`
+	N      int          `json:"n,string"`
+	P      *float64     `json:"p,omitempty"`
+	Slash  bool         `json:"a/b~c"`
+	Inner  XMergeInner  `json:"inner"`
+	PInner *XMergeInner `json:"pinner,omitempty"`
+	List   []int        `json:"list"`
+}
+
+// XViewInner struct
+type XViewInner struct {
+	Public string `json:"public"`
+	Secret string `json:"secret" ffjson:"views=admin"`
+}
+
+// XViewKey is a map key type
+type XViewKey string
+
+// XView struct
+type XView struct {
+	ID     int                      `json:"id"`
+	Email  string                   `json:"email,omitempty" ffjson:"views=admin|internal"`
+	Notes  string                   `json:"notes" ffjson:"views=internal"`
+	Inner  XViewInner               `json:"inner"`
+	PInner *XViewInner              `json:"pinner"`
+	List   []XViewInner             `json:"list"`
+	ByName map[string]*XViewInner   `json:"byName"`
+	ByKey  map[XViewKey]*XViewInner `json:"byKey"`
+	Anon   struct {
+		Public string `json:"public"`
+		Secret string `json:"secret" ffjson:"views=admin"`
+	} `json:"anon"`
+	Last bool `json:"last" ffjson:"views=admin"`
+}
+
+// XRedactInner struct
+type XRedactInner struct {
+	Name  string `json:"name"`
+	Token string `json:"token" ffjson:"redact"`
+}
+
+// XRedact struct
+type XRedact struct {
+	User     string          `json:"user"`
+	Password string          `json:"password" ffjson:"redact"`
+	Card     string          `json:"card" ffjson:"redact=mask"`
+	PCard    *string         `json:"pcard" ffjson:"redact=mask"`
+	SSN      string          `json:"ssn,omitempty" ffjson:"redact=hash"`
+	PIN      int             `json:"pin,string" ffjson:"redact"`
+	Count    int             `json:"count,string" ffjson:"redact=hash"`
+	Key      []byte          `json:"key" ffjson:"redact=omit"`
+	Inner    XRedactInner    `json:"inner"`
+	Hashed   *XRedactInner   `json:"hashed" ffjson:"redact=hash"`
+	Items    []*XRedactInner `json:"items"`
+	Anon     struct {
+		Secret string `json:"secret" ffjson:"redact"`
+		Hidden bool   `json:"hidden" ffjson:"redact=omit"`
+	} `json:"anon"`
+	Last bool `json:"last" ffjson:"redact=omit"`
+}
+
+// XCodec struct
+type XCodec struct {
+	Price   codec.Cents  `json:"price" ffjson:"codec=github.com/pquerna/ffjson/tests/codec.CentsCodec"`
+	PPrice  *codec.Cents `json:"pprice,omitempty" ffjson:"codec=github.com/pquerna/ffjson/tests/codec.CentsCodec"`
+	NPrice  *codec.Cents `json:"nprice" ffjson:"codec=github.com/pquerna/ffjson/tests/codec.CentsCodec"`
+	Point   XPoint       `json:"point" ffjson:"codec=xPointCodec"`
+	Plain   codec.Cents  `json:"plain"`
+	Dollars codec.Cents  `json:"dollars,omitempty" ffjson:"codec=github.com/pquerna/ffjson/tests/codec/v2.DollarsCodec"`
+}
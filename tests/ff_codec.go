@@ -0,0 +1,57 @@
+/**
+ *  Copyright 2016 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"encoding/json"
+
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+)
+
+// XPoint is written as [x, y] by xPointCodec. It is not in ff.go, so it
+// has no generated methods.
+type XPoint struct {
+	X, Y int
+}
+
+var xPointCodec pointCodec
+
+type pointCodec struct{}
+
+func (pointCodec) Encode(buf fflib.EncodingBuffer, v XPoint) error {
+	buf.WriteByte('[')
+	fflib.FormatBits2(buf, uint64(v.X), 10, v.X < 0)
+	buf.WriteByte(',')
+	fflib.FormatBits2(buf, uint64(v.Y), 10, v.Y < 0)
+	buf.WriteByte(']')
+	return nil
+}
+
+func (pointCodec) Decode(fs *fflib.FFLexer, tok fflib.FFTok, v *XPoint) error {
+	data, err := fs.CaptureField(tok)
+	if err != nil {
+		return err
+	}
+	var xy [2]int
+	err = json.Unmarshal(data, &xy)
+	if err != nil {
+		return err
+	}
+	v.X, v.Y = xy[0], xy[1]
+	return nil
+}
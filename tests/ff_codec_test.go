@@ -0,0 +1,84 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"testing"
+
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"github.com/pquerna/ffjson/tests/codec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldCodec(t *testing.T) {
+	pprice := codec.Cents(-5)
+	x := &XCodec{
+		Price:  1234,
+		PPrice: &pprice,
+		Point:  XPoint{X: 1, Y: -2},
+		Plain:  99,
+	}
+
+	data, err := x.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{ "price":"12.34","pprice":"-0.05","nprice":null,"point":[1,-2],"plain":99}`, string(data))
+
+	var got XCodec
+	require.NoError(t, got.UnmarshalJSON(data))
+	require.Equal(t, x, &got)
+
+	got = XCodec{NPrice: &pprice}
+	require.NoError(t, got.UnmarshalJSON([]byte(`{"price":7.5,"nprice":null,"point":[3,4]}`)))
+	require.Equal(t, XCodec{Price: 750, Point: XPoint{X: 3, Y: 4}}, got)
+
+	require.Error(t, got.UnmarshalJSON([]byte(`{"price":true}`)))
+	require.Error(t, got.UnmarshalJSON([]byte(`{"point":[1,"x"]}`)))
+}
+
+// The package of DollarsCodec is named codec, not v2.
+func TestFieldCodecImportName(t *testing.T) {
+	x := &XCodec{Dollars: 1250}
+	data, err := x.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{ "price":"0.00","nprice":null,"point":[0,0],"plain":0,"dollars":12.5}`, string(data))
+
+	var got XCodec
+	require.NoError(t, got.UnmarshalJSON([]byte(`{"dollars":0.07}`)))
+	require.Equal(t, codec.Cents(7), got.Dollars)
+}
+
+func TestFieldCodecDiff(t *testing.T) {
+	pprice := codec.Cents(-5)
+	a := &XCodec{}
+	b := &XCodec{Price: 1234, PPrice: &pprice, Point: XPoint{X: 1, Y: 2}}
+
+	ops := a.DiffJSON(b)
+	patch, err := fflib.MarshalPatch(ops)
+	require.NoError(t, err)
+	require.Equal(t, `[{"op":"replace","path":"/price","value":"12.34"},`+
+		`{"op":"add","path":"/pprice","value":"-0.05"},`+
+		`{"op":"replace","path":"/point","value":[1,2]}]`, string(patch))
+	requireDiff(t, a, b, ops)
+	requireDiff(t, b, a, b.DiffJSON(a))
+
+	// The values are copied when diffing.
+	b.Price = 1
+	patch2, err := fflib.MarshalPatch(ops)
+	require.NoError(t, err)
+	require.Equal(t, string(patch), string(patch2))
+}
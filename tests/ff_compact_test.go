@@ -0,0 +1,117 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pquerna/ffjson/ffjson"
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"github.com/stretchr/testify/require"
+)
+
+var compactInputs = []string{
+	`{}`,
+	` [ ] `,
+	`1`,
+	`-0.5e+10`,
+	`"str"`,
+	`true`,
+	`null`,
+	"\t{ \"a\" : [ 1, 2.5 , {\"b\":null} ],\n \"c\": \"x \u00e9\\\"\\n</\" }\n",
+	`[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]`,
+	"[\"\xe2\x80\xa8\"]",
+
+	``,
+	`   `,
+	`{`,
+	`[1,]`,
+	`[1 2]`,
+	`{"a" 1}`,
+	`{"a":1,}`,
+	`{1:2}`,
+	`[}`,
+	`]`,
+	`1 2`,
+	`{} x`,
+	`-`,
+	`1.`,
+	`"abc`,
+	`"\x"`,
+	`nul`,
+	`/* comment */ 1`,
+	`[1, // comment` + "\n" + `2]`,
+
+	// Only space, tab, newline and carriage return are whitespace.
+	"\f[]",
+	"[]\f",
+	"[\v1]",
+	"{\"a\":\f1}",
+	"\v",
+	"{} \n ",
+	"1\n\n  ",
+	"[1] \r\n\t",
+	"\"s\" \n \n ",
+}
+
+func TestValid(t *testing.T) {
+	for _, input := range compactInputs {
+		require.Equal(t, json.Valid([]byte(input)), ffjson.Valid([]byte(input)), input)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	for _, input := range compactInputs {
+		expect := bytes.NewBufferString("prefix")
+		expectErr := json.Compact(expect, []byte(input))
+		out := bytes.NewBufferString("prefix")
+		err := ffjson.Compact(out, []byte(input))
+		require.Equal(t, expectErr == nil, err == nil, input)
+		require.Equal(t, expect.String(), out.String(), input)
+
+		if err == nil {
+			var buf fflib.Buffer
+			require.NoError(t, ffjson.CompactBuf(&buf, []byte(input)))
+			require.Equal(t, expect.String(), "prefix"+buf.String())
+		}
+	}
+}
+
+func TestIndent(t *testing.T) {
+	for _, input := range compactInputs {
+		for _, prefix := range []string{"", "> ", ">", "  "} {
+			expect := bytes.NewBufferString("prefix")
+			expectErr := json.Indent(expect, []byte(input), prefix, "\t")
+			out := bytes.NewBufferString("prefix")
+			err := ffjson.Indent(out, []byte(input), prefix, "\t")
+			require.Equal(t, expectErr == nil, err == nil, input)
+			require.Equal(t, expect.String(), out.String(), "%q with prefix %q", input, prefix)
+		}
+	}
+}
+
+func TestValidAllocs(t *testing.T) {
+	data := []byte(compactInputs[7])
+	require.True(t, ffjson.Valid(data))
+	allocs := testing.AllocsPerRun(100, func() {
+		ffjson.Valid(data)
+	})
+	require.Equal(t, float64(0), allocs)
+}
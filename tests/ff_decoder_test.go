@@ -0,0 +1,190 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pquerna/ffjson/ffjson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrailingData(t *testing.T) {
+	invalid := []string{
+		`{"X": 1}garbage`,
+		`{"X": 1} {"X": 2}`,
+		`{"X": 1}}`,
+		`{"X": 1} ,`,
+	}
+	for _, input := range invalid {
+		var std Tint
+		require.Error(t, json.Unmarshal([]byte(input), &std), input)
+
+		var v Xint
+		require.Error(t, ffjson.Unmarshal([]byte(input), &v), input)
+		require.Error(t, v.UnmarshalJSON([]byte(input)), input)
+		require.Error(t, ffjson.NewDecoder().Decode([]byte(input), &v), input)
+		require.Error(t, ffjson.NewDecoder().DecodeFast([]byte(input), &v), input)
+	}
+
+	var v Xint
+	require.NoError(t, ffjson.Unmarshal([]byte(" {\"X\": 1} \n\t\r "), &v))
+	require.Equal(t, 1, v.X)
+	require.NoError(t, ffjson.NewDecoder().Decode([]byte(`{"X": 2}`+"\n"), &v))
+	require.Equal(t, 2, v.X)
+}
+
+func TestDecoderNext(t *testing.T) {
+	input := []byte(`{"X": 1}{"X": 2}  {"X": "s"}` + "\n" + `{"X": 3} [4] `)
+	dec := ffjson.NewDecoder()
+	dec.Reset(input)
+
+	var x Xint
+	require.True(t, dec.More())
+	require.NoError(t, dec.Next(&x))
+	require.Equal(t, 1, x.X)
+	require.Equal(t, 8, dec.InputOffset())
+
+	require.NoError(t, dec.Next(&x))
+	require.Equal(t, 2, x.X)
+	require.Equal(t, 16, dec.InputOffset())
+
+	// Values without generated code go through encoding/json.
+	var s Tstring
+	require.NoError(t, dec.Next(&s))
+	require.Equal(t, "s", s.X)
+	require.Equal(t, 28, dec.InputOffset())
+
+	require.NoError(t, dec.Next(&x))
+	require.Equal(t, 3, x.X)
+	require.Equal(t, 37, dec.InputOffset())
+
+	var a []int
+	require.True(t, dec.More())
+	require.NoError(t, dec.Next(&a))
+	require.Equal(t, []int{4}, a)
+	require.Equal(t, 41, dec.InputOffset())
+
+	require.False(t, dec.More())
+	require.Equal(t, io.EOF, dec.Next(&x))
+	require.Equal(t, 41, dec.InputOffset())
+}
+
+func TestDecoderNextError(t *testing.T) {
+	dec := ffjson.NewDecoder()
+	dec.Reset([]byte(`{"X": 1} {"X": "bad"} {"X": 3}`))
+
+	var x Xint
+	require.NoError(t, dec.Next(&x))
+	err := dec.Next(&x)
+	require.Error(t, err)
+	require.False(t, dec.More())
+	require.Equal(t, err, dec.Next(&x))
+
+	dec.Reset([]byte(`{"X": 5}`))
+	require.NoError(t, dec.Next(&x))
+	require.Equal(t, 5, x.X)
+	require.False(t, dec.More())
+}
+
+// Only space, tab, newline and carriage return are whitespace, as in
+// encoding/json, both between and inside values.
+func TestDecoderWhitespace(t *testing.T) {
+	for _, ws := range []string{"\v", "\f"} {
+		dec := ffjson.NewDecoder()
+		dec.Reset([]byte(`{"X": 1}` + ws + `{"X": 2}`))
+
+		var x Xint
+		require.NoError(t, dec.Next(&x))
+		require.True(t, dec.More())
+		require.Error(t, dec.Next(&x))
+
+		require.Error(t, json.Unmarshal([]byte(`{"X":`+ws+`1}`), &x))
+		require.Error(t, ffjson.Unmarshal([]byte(`{"X":`+ws+`1}`), &x))
+		require.Error(t, ffjson.Unmarshal([]byte(`{"X": 1}`+ws), &x))
+	}
+}
+
+func TestDecoderPool(t *testing.T) {
+	var pool ffjson.DecoderPool
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				var v Xint
+				err := pool.Decode([]byte(`{"X": `+strconv.Itoa(g*1000+i)+`}`), &v)
+				if err != nil || v.X != g*1000+i {
+					t.Errorf("unexpected result %d, %v", v.X, err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	d := pool.Get()
+	d.UseNumber()
+	pool.Put(d)
+	var v map[string]interface{}
+	require.NoError(t, pool.DecodeReader(strings.NewReader(`{"X": 1}`), &v))
+	require.Equal(t, float64(1), v["X"])
+}
+
+// A lexer that failed while capturing a value must be usable again once it
+// is back in the pool.
+func TestPoolAfterFailedCapture(t *testing.T) {
+	bad := []byte(`{"Omap": {"a": [1, 2`)
+	good := []byte(`{"Ostr":"hello","Oint":5,"Omap":{"x":1}}`)
+
+	var pool ffjson.DecoderPool
+	for i := 0; i < 10; i++ {
+		var v OmitAll
+		require.Error(t, ffjson.Unmarshal(bad, &v))
+		require.Error(t, pool.Decode(bad, &v))
+
+		v = OmitAll{}
+		require.NoError(t, ffjson.Unmarshal(good, &v))
+		require.Equal(t, "hello", v.Ostr)
+		require.Equal(t, 5, v.Oint)
+		require.Equal(t, map[string]interface{}{"x": float64(1)}, v.Omap)
+
+		v = OmitAll{}
+		require.NoError(t, pool.Decode(good, &v))
+		require.Equal(t, "hello", v.Ostr)
+	}
+}
+
+func TestUnmarshalAllocs(t *testing.T) {
+	data := []byte(`{"X": 12345}`)
+	var v Xint
+	require.NoError(t, ffjson.Unmarshal(data, &v))
+
+	var pool ffjson.DecoderPool
+	allocs := testing.AllocsPerRun(100, func() {
+		ffjson.Unmarshal(data, &v)
+		pool.Decode(data, &v)
+	})
+	require.Equal(t, float64(0), allocs)
+}
@@ -0,0 +1,124 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// applyPatch applies the add, remove and replace operations of an
+// RFC 6902 JSON Patch to objects decoded with encoding/json.
+func applyPatch(t *testing.T, doc interface{}, patch []byte) interface{} {
+	var ops []struct {
+		Op    string
+		Path  string
+		Value json.RawMessage
+	}
+	require.NoError(t, json.Unmarshal(patch, &ops))
+
+	for _, op := range ops {
+		segs := strings.Split(op.Path, "/")[1:]
+		obj := doc.(map[string]interface{})
+		for _, seg := range segs[:len(segs)-1] {
+			obj = obj[unescapeSeg(seg)].(map[string]interface{})
+		}
+		key := unescapeSeg(segs[len(segs)-1])
+
+		_, exists := obj[key]
+		switch op.Op {
+		case "remove":
+			require.True(t, exists, op.Path)
+			delete(obj, key)
+		case "add", "replace":
+			require.Equal(t, op.Op == "replace", exists, op.Path)
+			var v interface{}
+			require.NoError(t, json.Unmarshal(op.Value, &v))
+			obj[key] = v
+		default:
+			t.Fatalf("unexpected op %s", op.Op)
+		}
+	}
+	return doc
+}
+
+func unescapeSeg(s string) string {
+	return strings.Replace(strings.Replace(s, "~1", "/", -1), "~0", "~", -1)
+}
+
+func requireDiff(t *testing.T, from, to interface {
+	MarshalJSON() ([]byte, error)
+}, ops []fflib.PatchOp) {
+	patch, err := fflib.MarshalPatch(ops)
+	require.NoError(t, err)
+
+	var doc, expect interface{}
+	data, err := json.Marshal(from)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &doc))
+	data, err = json.Marshal(to)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &expect))
+
+	require.Equal(t, expect, applyPatch(t, doc, patch), string(patch))
+}
+
+func TestDiffJSON(t *testing.T) {
+	p := 1.5
+	a := &XDiff{Name: "a", N: 1, Inner: XMergeInner{A: "x"}, List: []int{1}}
+	b := &XDiff{Name: "b", Opt: "set", N: 2, P: &p, Slash: true, Inner: XMergeInner{A: "x", B: 2},
+		PInner: &XMergeInner{A: "new"}, List: []int{1, 2}}
+
+	require.Empty(t, a.DiffJSON(a))
+
+	ops := a.DiffJSON(b)
+	patch, err := fflib.MarshalPatch(ops)
+	require.NoError(t, err)
+	require.Equal(t, `[{"op":"replace","path":"/name","value":"b"},`+
+		`{"op":"add","path":"/opt","value":"set"},`+
+		`{"op":"replace","path":"/n","value":"2"},`+
+		`{"op":"add","path":"/p","value":1.5},`+
+		`{"op":"replace","path":"/a~1b~0c","value":true},`+
+		`{"op":"add","path":"/inner/b","value":2},`+
+		`{"op":"add","path":"/pinner","value":{ "a":"new"}},`+
+		`{"op":"replace","path":"/list","value":[1,2]}]`, string(patch))
+	requireDiff(t, a, b, ops)
+	requireDiff(t, b, a, b.DiffJSON(a))
+
+	c := *b
+	c.PInner = &XMergeInner{A: "changed", B: 3}
+	ops = b.DiffJSON(&c)
+	require.Equal(t, []fflib.PatchOp{
+		{Op: "replace", Path: "/pinner/a", Value: "changed"},
+		{Op: "add", Path: "/pinner/b", Value: 3},
+	}, ops)
+	requireDiff(t, b, &c, ops)
+
+	n := 7
+	m1 := newMergeTarget()
+	m2 := newMergeTarget()
+	m2.Ptr = &n
+	m2.PInner = nil
+	m2.Labels["env"] = "dev"
+	requireDiff(t, m1, m2, m1.DiffJSON(m2))
+	requireDiff(t, m2, m1, m2.DiffJSON(m1))
+}
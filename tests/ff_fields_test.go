@@ -0,0 +1,86 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"encoding/json"
+	"testing"
+
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalJSONFields(t *testing.T) {
+	p := 2.5
+	values := []*XDiff{
+		{},
+		{Name: "n", Opt: "o", N: 3, P: &p, Slash: true, Inner: XMergeInner{A: "a", B: 1},
+			PInner: &XMergeInner{B: 2}, List: []int{1, 2}},
+	}
+	sets := [][]string{
+		nil,
+		{"name"},
+		{"list"},
+		{"opt"},
+		{"opt", "p", "pinner"},
+		{"a/b~c", "n", "inner", "unknown"},
+		{"name", "opt", "n", "p", "a/b~c", "inner", "pinner", "list"},
+	}
+
+	for _, v := range values {
+		var all map[string]interface{}
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &all))
+
+		for _, names := range sets {
+			expect := map[string]interface{}{}
+			for _, name := range names {
+				if val, ok := all[name]; ok {
+					expect[name] = val
+				}
+			}
+
+			var buf fflib.Buffer
+			require.NoError(t, v.MarshalJSONFields(&buf, v.JSONFieldSet(names...)))
+			var got map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got), buf.String())
+			require.Equal(t, expect, got, "%v: %s", names, buf.String())
+		}
+	}
+}
+
+func TestMarshalJSONFieldsEmpty(t *testing.T) {
+	var buf fflib.Buffer
+	v := &XDiff{Opt: "set"}
+	require.NoError(t, v.MarshalJSONFields(&buf, nil))
+	require.Equal(t, `{}`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, v.MarshalJSONFields(&buf, v.JSONFieldSet("opt")))
+	require.Equal(t, `{ "opt":"set"}`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, v.MarshalJSONFields(&buf, v.JSONFieldSet("p")))
+	require.Equal(t, `{}`, buf.String())
+
+	buf.Reset()
+	var nilv *XDiff
+	require.NoError(t, nilv.MarshalJSONFields(&buf, v.JSONFieldSet("opt")))
+	require.Equal(t, `null`, buf.String())
+}
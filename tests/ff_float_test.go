@@ -18,7 +18,12 @@
 package tff
 
 import (
+	"encoding/json"
+	"math"
 	"testing"
+
+	"github.com/pquerna/ffjson/ffjson"
+	"github.com/stretchr/testify/require"
 )
 
 // Test data from https://github.com/akheron/jansson/tree/master/test/suites/valid
@@ -101,3 +106,36 @@ func TestFloatInt(t *testing.T) {
 		`1`,
 		&Xfloat64{})
 }
+
+func TestFloatSameMarshal(t *testing.T) {
+	values := []float64{
+		0, 1, -1, 0.1, 1e20, 1e21, 123456789e13, 1e-6, 1e-7, 0.0000001,
+		-2.5e-8, 1.7976931348623157e308, 5e-324, 3.141592653, 100, 1e100,
+	}
+
+	for _, v := range values {
+		testSameMarshal(t, &Tfloat64{X: v}, &Xfloat64{X: v})
+		if math.Abs(v) <= math.MaxFloat32 {
+			testSameMarshal(t, &Tfloat32{X: float32(v)}, &Xfloat32{X: float32(v)})
+		}
+		testSameMarshal(t, &STfloat64{X: []float64{v, -v}}, &SXfloat64{X: []float64{v, -v}})
+	}
+}
+
+func TestFloatUnsupportedValues(t *testing.T) {
+	for _, v := range unsupportedValues {
+		f := v.(float64)
+		_, err := ffjson.MarshalFast(&Xfloat64{X: f})
+		require.Error(t, err, "for %v, expected error", v)
+		require.IsType(t, &json.UnsupportedValueError{}, err, "for %v", v)
+
+		_, err = ffjson.MarshalFast(&Xfloat32{X: float32(f)})
+		require.IsType(t, &json.UnsupportedValueError{}, err, "for %v", v)
+
+		_, err = ffjson.MarshalFast(&SXfloat64{X: []float64{1, f}})
+		require.IsType(t, &json.UnsupportedValueError{}, err, "for %v", v)
+	}
+
+	_, err := ffjson.MarshalFast(&Xfloat64{X: math.MaxFloat64})
+	require.NoError(t, err)
+}
@@ -20,8 +20,10 @@ package tff
 import (
 	fflib "github.com/pquerna/ffjson/fflib/v1"
 
-	_ "encoding/json"
+	"encoding/json"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 // Test data from https://github.com/akheron/jansson/tree/master/test/suites/invalid
@@ -188,3 +190,75 @@ func TestInvalidFloatGarbageAfterE(t *testing.T) {
 		`1ea`,
 		&Xfloat64{})
 }
+
+func TestInvalidUnknownFieldMismatchedBrackets(t *testing.T) {
+	testExpectedError(t,
+		&fflib.LexerError{},
+		`{"X": "a", "unknown": {"b": [1, 2}}`,
+		&Xstring{})
+}
+
+func TestInvalidUnknownFieldUnterminatedString(t *testing.T) {
+	testExpectedError(t,
+		&fflib.LexerError{},
+		`{"X": "a", "unknown": ["b]}`,
+		&Xstring{})
+}
+
+// Unknown fields are skipped without being decoded, but must still be
+// valid JSON.
+func TestInvalidUnknownFieldStructure(t *testing.T) {
+	invalid := []string{
+		`[1 2]`,
+		`[tru]`,
+		`[nul]`,
+		`[falsey]`,
+		`[true1]`,
+		`{"a" 1}`,
+		`{"a":1 "b":2}`,
+		`{"a":}`,
+		`{1:2}`,
+		`{"a"}`,
+		`{"a"::1}`,
+		`[1:2]`,
+		`[,1]`,
+		`[1,]`,
+		`{"a":1,}`,
+		`[01]`,
+		`[1.]`,
+		`[.5]`,
+		`[1e]`,
+		`[1e+]`,
+		`[-]`,
+		`[--1]`,
+		`[+1]`,
+		`[1-2]`,
+		`["\u12G4"]`,
+		`["\u12"]`,
+	}
+	for _, unknown := range invalid {
+		input := []byte(`{"X": "a", "unknown": ` + unknown + `}`)
+		var std, ff Xstring
+		require.Error(t, json.Unmarshal(input, &std), "encoding/json accepted %s", unknown)
+		err := ff.UnmarshalJSON(input)
+		require.Error(t, err, "ffjson accepted %s", unknown)
+		require.IsType(t, &fflib.LexerError{}, err)
+	}
+}
+
+func TestUnknownFieldValid(t *testing.T) {
+	valid := []string{
+		`[]`,
+		`{}`,
+		`[-0.5e+10, 0, -0, 1E-2, 10.25, true, false, null, "\u00e9\"]", {}, []]`,
+		`{"a": [{"b": {}}], "c" : "d" , "e":null}`,
+		"[\n\t1 ,\r 2\n]",
+	}
+	for _, unknown := range valid {
+		input := []byte(`{"X": "a", "unknown": ` + unknown + `, "X": "b"}`)
+		var std, ff Xstring
+		require.NoError(t, json.Unmarshal(input, &std), unknown)
+		require.NoError(t, ff.UnmarshalJSON(input), unknown)
+		require.Equal(t, std, ff)
+	}
+}
@@ -0,0 +1,110 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pquerna/ffjson/ffjson"
+	"github.com/stretchr/testify/require"
+)
+
+type indentedMarshaler struct{}
+
+func (indentedMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte("{\n  \"a\": [\n    1\n  ]\n}"), nil
+}
+
+func TestLineEncoder(t *testing.T) {
+	var out bytes.Buffer
+	enc := ffjson.NewLineEncoder(&out)
+
+	require.NoError(t, enc.Encode(&Xint{X: 1}))
+	require.NoError(t, enc.Encode(&Xstring{X: "line\nbreak"}))
+	require.NoError(t, enc.Encode(&Tstring{X: "fallback"}))
+	require.NoError(t, enc.Encode(indentedMarshaler{}))
+
+	require.Equal(t, "{\"X\":1}\n{\"X\":\"line\\nbreak\"}\n{\"X\":\"fallback\"}\n{\"a\":[1]}\n", out.String())
+}
+
+func TestLineDecoder(t *testing.T) {
+	long := strings.Repeat("x", 10000)
+	input := "{\"X\": 1}\n" +
+		"\n" +
+		"{\"X\": 2}\r\n" +
+		"{\"X\": \"bad\"}\n" +
+		"{\"X\": 3} {\"X\": 4}\n" +
+		"  \t\n" +
+		"{\"X\": 5}"
+
+	dec := ffjson.NewLineDecoder(strings.NewReader(input))
+
+	var values []int
+	var errLines []int
+	for dec.More() {
+		var v Xint
+		err := dec.Next(&v)
+		if err != nil {
+			lerr, ok := err.(*ffjson.LineError)
+			require.True(t, ok, "expected a *LineError, got %T", err)
+			errLines = append(errLines, lerr.Line)
+			continue
+		}
+		values = append(values, v.X)
+	}
+
+	require.Equal(t, []int{1, 2, 5}, values)
+	require.Equal(t, []int{4, 5}, errLines)
+	require.Equal(t, io.EOF, dec.Next(&Xint{}))
+
+	dec = ffjson.NewLineDecoder(strings.NewReader("{\"X\": \"" + long + "\"}\n{\"X\": \"short\"}\n"))
+	var s Xstring
+	require.NoError(t, dec.Next(&s))
+	require.Equal(t, long, s.X)
+	require.NoError(t, dec.Next(&s))
+	require.Equal(t, "short", s.X)
+	require.False(t, dec.More())
+
+	dec = ffjson.NewLineDecoder(strings.NewReader("{\"X\": \"a\"}\n{\"X\": 1}\n"))
+	var ts Tstring
+	require.NoError(t, dec.Next(&ts))
+	require.Equal(t, "a", ts.X)
+	err := dec.Next(&ts)
+	require.IsType(t, &ffjson.LineError{}, err)
+	require.Equal(t, 2, err.(*ffjson.LineError).Line)
+}
+
+func TestLineRoundTrip(t *testing.T) {
+	var out bytes.Buffer
+	enc := ffjson.NewLineEncoder(&out)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, enc.Encode(&Xint{X: i}))
+	}
+
+	dec := ffjson.NewLineDecoder(&out)
+	for i := 0; i < 100; i++ {
+		require.True(t, dec.More())
+		var v Xint
+		require.NoError(t, dec.Next(&v))
+		require.Equal(t, i, v.X)
+	}
+	require.False(t, dec.More())
+}
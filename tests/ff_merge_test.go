@@ -0,0 +1,106 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mergePatch is the MergePatch function of RFC 7396, section 2.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+func newMergeTarget() *XMergePatch {
+	n := 7
+	return &XMergePatch{
+		Title:  "title",
+		Count:  3,
+		Ptr:    &n,
+		Inner:  XMergeInner{A: "a", B: 1},
+		PInner: &XMergeInner{A: "pa", B: 2},
+		Tags:   []string{"x", "y"},
+		Labels: map[string]string{"env": "prod", "team": "core"},
+		Items:  map[string]*XMergeInner{"one": {A: "1", B: 1}, "two": {A: "2"}},
+	}
+}
+
+func TestMergeJSONPatch(t *testing.T) {
+	patches := []string{
+		`{}`,
+		`{"title": "new", "unknown": {"x": 1}}`,
+		`{"title": null, "count": null, "ptr": null, "inner": null, "pinner": null, "tags": null, "labels": null, "items": null}`,
+		`{"inner": {"b": 5}, "pinner": {"a": null}}`,
+		`{"tags": ["z"], "labels": {"env": "dev", "team": null, "new": "label"}}`,
+		`{"items": {"one": {"a": "uno"}, "two": null, "three": {"b": 3}}}`,
+		`{"ptr": 9, "count": 4}`,
+	}
+
+	for _, patch := range patches {
+		v := newMergeTarget()
+		require.NoError(t, v.MergeJSONPatch([]byte(patch)), patch)
+
+		// The same through marshaling and a generic merge.
+		var doc, p interface{}
+		data, err := json.Marshal(newMergeTarget())
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &doc))
+		require.NoError(t, json.Unmarshal([]byte(patch), &p))
+		merged, err := json.Marshal(mergePatch(doc, p))
+		require.NoError(t, err)
+		var expect XMergePatch
+		require.NoError(t, json.Unmarshal(merged, &expect))
+
+		require.Equal(t, &expect, v, patch)
+
+		// Without MergePatch, the same decoder unmarshals as usual.
+		u, expect2 := newMergeTarget(), newMergeTarget()
+		require.NoError(t, u.UnmarshalJSON([]byte(patch)), patch)
+		require.NoError(t, json.Unmarshal([]byte(patch), expect2), patch)
+		require.Equal(t, expect2, u, patch)
+	}
+
+	// A nil pointer or map is created as needed.
+	var v XMergePatch
+	require.NoError(t, v.MergeJSONPatch([]byte(`{"pinner": {"a": "x"}, "labels": {"k": "v"}, "items": {"i": {"b": 1}}}`)))
+	require.Equal(t, &XMergeInner{A: "x"}, v.PInner)
+	require.Equal(t, map[string]string{"k": "v"}, v.Labels)
+	require.Equal(t, &XMergeInner{B: 1}, v.Items["i"])
+
+	for _, patch := range []string{`[]`, `{"title": 1}`, `{"labels": {"k" "v"}}`, `{} x`} {
+		require.Error(t, v.MergeJSONPatch([]byte(patch)), patch)
+	}
+}
@@ -0,0 +1,165 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"testing"
+
+	"github.com/pquerna/ffjson/ffjson"
+	"github.com/stretchr/testify/require"
+)
+
+// The example document of RFC 6901, section 5.
+const pointerDoc = ` {"foo": ["bar", "baz"], "": 0, "a/b": 1, "c%d": 2, "e^f": 3, "g|h": 4,
+	"i\\j": 5, "k\"l": 6, " ": 7, "m~n": 8, "deep": {"x": [{"y": [true, null]}]}} `
+
+var pointerTests = []struct {
+	pointer string
+	raw     string
+}{
+	{"", pointerDoc[1 : len(pointerDoc)-1]},
+	{"/foo", `["bar", "baz"]`},
+	{"/foo/0", `"bar"`},
+	{"/foo/1", `"baz"`},
+	{"/", `0`},
+	{"/a~1b", `1`},
+	{"/c%d", `2`},
+	{"/e^f", `3`},
+	{"/g|h", `4`},
+	{`/i\j`, `5`},
+	{`/k"l`, `6`},
+	{"/ ", `7`},
+	{"/m~0n", `8`},
+	{"/deep/x/0/y", `[true, null]`},
+	{"/deep/x/0/y/1", `null`},
+
+	{"/missing", ""},
+	{"/foo/2", ""},
+	{"/foo/-", ""},
+	{"/foo/01", ""},
+	{"/foo/x", ""},
+	{"/foo/0/bar", ""},
+	{"/a~1b/c", ""},
+	{"/deep/x/0/z", ""},
+}
+
+func TestGet(t *testing.T) {
+	for _, test := range pointerTests {
+		raw, err := ffjson.Get([]byte(pointerDoc), test.pointer)
+		if test.raw == "" {
+			require.Equal(t, ffjson.ErrPointerNotFound, err, test.pointer)
+			continue
+		}
+		require.NoError(t, err, test.pointer)
+		require.Equal(t, test.raw, string(raw), test.pointer)
+	}
+
+	for _, pointer := range []string{"foo", "/m~2n", "/m~"} {
+		_, err := ffjson.Get([]byte(pointerDoc), pointer)
+		require.Error(t, err, pointer)
+		require.NotEqual(t, ffjson.ErrPointerNotFound, err, pointer)
+	}
+
+	for _, doc := range []string{`{"a": [}`, `{"b": 1 "a": 1}`, `{"a"`, `{"a": [[1, 2`} {
+		_, err := ffjson.Get([]byte(doc), "/a/0")
+		require.Error(t, err, doc)
+		require.NotEqual(t, ffjson.ErrPointerNotFound, err, doc)
+	}
+}
+
+func TestGetScalar(t *testing.T) {
+	data := []byte(`{"meta": {"request_id": "réq-1", "n": -12, "f": 1.5, "ok": true, "nil": null}}`)
+
+	tok, err := ffjson.GetScalar(data, "/meta/request_id")
+	require.NoError(t, err)
+	require.Equal(t, ffjson.TokenString, tok.Kind)
+	require.Equal(t, "réq-1", tok.String())
+
+	tok, err = ffjson.GetScalar(data, "/meta/n")
+	require.NoError(t, err)
+	n, err := tok.Int()
+	require.NoError(t, err)
+	require.Equal(t, int64(-12), n)
+
+	tok, err = ffjson.GetScalar(data, "/meta/f")
+	require.NoError(t, err)
+	f, err := tok.Float()
+	require.NoError(t, err)
+	require.Equal(t, 1.5, f)
+
+	tok, err = ffjson.GetScalar(data, "/meta/ok")
+	require.NoError(t, err)
+	require.True(t, tok.Bool())
+
+	tok, err = ffjson.GetScalar(data, "/meta/nil")
+	require.NoError(t, err)
+	require.Equal(t, ffjson.TokenNull, tok.Kind)
+
+	_, err = ffjson.GetScalar(data, "/meta")
+	require.Error(t, err)
+}
+
+func TestPointerSet(t *testing.T) {
+	var pointers []string
+	for _, test := range pointerTests {
+		pointers = append(pointers, test.pointer)
+	}
+	// Twice the same pointer, and one found before its parent ends.
+	pointers = append(pointers, "/foo/0", "/deep/x")
+
+	ps, err := ffjson.CompilePointers(pointers...)
+	require.NoError(t, err)
+	require.Equal(t, len(pointers), ps.Len())
+
+	values, err := ps.Get([][]byte{[]byte("kept")}, []byte(pointerDoc))
+	require.NoError(t, err)
+	require.Equal(t, 1+len(pointers), len(values))
+	require.Equal(t, "kept", string(values[0]))
+	for i, pointer := range pointers {
+		expect, err := ffjson.Get([]byte(pointerDoc), pointer)
+		if err == ffjson.ErrPointerNotFound {
+			require.Nil(t, values[1+i], pointer)
+			continue
+		}
+		require.Equal(t, string(expect), string(values[1+i]), pointer)
+	}
+
+	// The document is only read up to the last value.
+	ps, err = ffjson.CompilePointers("/a", "/b/0")
+	require.NoError(t, err)
+	values, err = ps.Get(nil, []byte(`{"b": [1, 2], "a": "x", ###`))
+	require.NoError(t, err)
+	require.Equal(t, []string{`"x"`, `1`}, []string{string(values[0]), string(values[1])})
+
+	_, err = ffjson.CompilePointers("/ok", "bad")
+	require.Error(t, err)
+}
+
+func TestGetNotFoundAllocs(t *testing.T) {
+	data := []byte(pointerDoc)
+	ps, err := ffjson.CompilePointers("/missing", "/deep/x/0/z")
+	require.NoError(t, err)
+	values := make([][]byte, 0, ps.Len())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ffjson.Get(data, "/deep/x/0/z")
+		ffjson.Get(data, "/foo/7")
+		ps.Get(values[:0], data)
+	})
+	require.Equal(t, float64(0), allocs)
+}
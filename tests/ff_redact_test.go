@@ -0,0 +1,126 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func redactedHash(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, []byte("test key"))
+	mac.Write(data)
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+func TestMarshalJSONRedacted(t *testing.T) {
+	fflib.SetRedactionKey([]byte("test key"))
+	defer fflib.SetRedactionKey(nil)
+
+	card := "4111111111111111"
+	x := &XRedact{
+		User:     "user",
+		Password: "hunter2",
+		Card:     card,
+		PCard:    &card,
+		SSN:      "078-05-1120",
+		PIN:      1234,
+		Count:    7,
+		Key:      []byte("key"),
+		Inner:    XRedactInner{Name: "inner", Token: "t1"},
+		Hashed:   &XRedactInner{Name: "hashed", Token: "t2"},
+		Items:    []*XRedactInner{{Name: "item", Token: "t3"}, nil},
+		Last:     true,
+	}
+	x.Anon.Secret = "s"
+	x.Anon.Hidden = true
+
+	var buf fflib.Buffer
+	require.NoError(t, x.MarshalJSONRedacted(&buf))
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got), buf.String())
+
+	inner := func(name string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "token": "[REDACTED]"}
+	}
+	require.Equal(t, map[string]interface{}{
+		"user":     "user",
+		"password": "[REDACTED]",
+		"card":     "************1111",
+		"pcard":    "************1111",
+		"ssn":      redactedHash(t, "078-05-1120"),
+		"pin":      "[REDACTED]",
+		"count":    redactedHash(t, "7"),
+		"inner":    inner("inner"),
+		"hashed":   redactedHash(t, x.Hashed),
+		"items":    []interface{}{inner("item"), nil},
+		"anon":     map[string]interface{}{"secret": "[REDACTED]"},
+	}, got, buf.String())
+
+	// The other encoders are not affected.
+	data, err := x.MarshalJSON()
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, "hunter2", got["password"])
+	require.Equal(t, true, got["last"])
+}
+
+func TestMarshalJSONRedactedEmpty(t *testing.T) {
+	var buf fflib.Buffer
+	x := &XRedact{}
+	require.NoError(t, x.MarshalJSONRedacted(&buf))
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got), buf.String())
+	require.Nil(t, got["pcard"])
+	require.Nil(t, got["hashed"])
+	require.NotContains(t, got, "ssn")
+	require.NotContains(t, got, "last")
+
+	buf.Reset()
+	var nilx *XRedact
+	require.NoError(t, nilx.MarshalJSONRedacted(&buf))
+	require.Equal(t, `null`, buf.String())
+}
+
+func TestMarshalJSONRedactedNamedKeys(t *testing.T) {
+	x := &XView{ByKey: map[XViewKey]*XViewInner{"k": {Public: "p", Secret: "s"}}}
+	var buf fflib.Buffer
+	require.NoError(t, x.MarshalJSONRedacted(&buf))
+	var got struct {
+		ByKey map[string]XViewInner `json:"byKey"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got), buf.String())
+	require.Equal(t, map[string]XViewInner{"k": {Public: "p", Secret: "s"}}, got.ByKey)
+}
+
+func TestMarshalJSONRedactedWithoutRedaction(t *testing.T) {
+	x := &XMergeInner{A: "a", B: 1}
+	var buf fflib.Buffer
+	require.NoError(t, x.MarshalJSONRedacted(&buf))
+	out, err := x.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, string(out), buf.String())
+}
@@ -28,6 +28,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -205,6 +206,169 @@ func TestMarshalEncoderError(t *testing.T) {
 	require.Error(t, err, "excpected error from encoder on type that isn't fast")
 }
 
+func TestMarshalAppend(t *testing.T) {
+	header := []byte("frame:")
+	values := []interface{}{
+		&Xint{X: 42},
+		&XslicePtrStruct{X: []*Xstring{{X: "a"}, {X: "b\n<"}}},
+		&XMapStringString{X: map[string]string{"k": "v"}},
+		(*Xint)(nil),
+		&Tstring{X: "fallback"},
+		indentedMarshaler{},
+	}
+	for _, v := range values {
+		expect, err := ffjson.Marshal(v)
+		require.NoError(t, err)
+
+		dst := append(make([]byte, 0, 4), header...)
+		out, err := ffjson.MarshalAppend(dst, v)
+		require.NoError(t, err)
+		require.Equal(t, "frame:"+string(expect), string(out))
+	}
+
+	x := &Xint{X: 7}
+	dst := make([]byte, 0, 64)
+	out, err := x.AppendJSON(dst)
+	require.NoError(t, err)
+	require.Equal(t, `{"X":7}`, string(out))
+	require.Equal(t, &dst[:1][0], &out[0], "expected AppendJSON to use the spare capacity of dst")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		out, _ = x.AppendJSON(dst)
+	})
+	require.True(t, allocs <= 1, "expected at most one allocation, got %v", allocs)
+
+	_, err = ffjson.MarshalAppend(header, GiveError{})
+	require.Error(t, err)
+	require.Equal(t, "frame:", string(header))
+}
+
+func TestMarshalIndent(t *testing.T) {
+	values := []interface{}{
+		&XslicePtrStruct{X: []*Xstring{{X: "a"}, {X: "{[\\\",:]} \n\t<>&"}}},
+		&XslicePtrStruct{},
+		&XslicePtrStruct{X: []*Xstring{}},
+		&XMapStringString{X: map[string]string{"k": "v"}},
+		&XMapStringString{X: map[string]string{}},
+		&Xint{X: -1},
+		(*Xint)(nil),
+		&Tstring{X: "fallback"},
+		indentedMarshaler{},
+	}
+	for _, v := range values {
+		for _, indent := range [][2]string{{"", "\t"}, {">", "  "}, {"", ""}} {
+			expect, err := json.MarshalIndent(v, indent[0], indent[1])
+			require.NoError(t, err)
+			out, err := ffjson.MarshalIndent(v, indent[0], indent[1])
+			require.NoError(t, err)
+			require.Equal(t, string(expect), string(out), "%T with %q", v, indent)
+		}
+	}
+
+	x := &XslicePtrStruct{}
+	for i := 0; i < 1000; i++ {
+		x.X = append(x.X, &Xstring{X: fmt.Sprintf("value %d", i)})
+	}
+	expect, err := json.MarshalIndent(x, "", "\t")
+	require.NoError(t, err)
+
+	out := bytes.Buffer{}
+	enc := ffjson.NewEncoder(&out)
+	enc.SetIndent("", "\t")
+	require.NoError(t, enc.Encode(x))
+	require.Equal(t, string(expect), out.String())
+
+	out.Reset()
+	enc.SetChunkSize(512)
+	require.NoError(t, enc.Encode(x))
+	require.Equal(t, string(expect), out.String())
+
+	out.Reset()
+	enc.SetIndent("", "")
+	require.NoError(t, enc.Encode(x))
+	compact, err := json.Marshal(x)
+	require.NoError(t, err)
+	require.Equal(t, string(compact), out.String())
+}
+
+func TestEncoderEscaping(t *testing.T) {
+	v := &XslicePtrStruct{X: []*Xstring{{X: "<a&b>\u00e9"}}}
+	// The same data without generated code anywhere.
+	ts := map[string][]map[string]string{"X": {{"X": "<a&b>\u00e9"}}}
+
+	for _, html := range []bool{true, false} {
+		var expect, out bytes.Buffer
+		stdEnc := json.NewEncoder(&expect)
+		stdEnc.SetEscapeHTML(html)
+		require.NoError(t, stdEnc.Encode(ts))
+
+		enc := ffjson.NewEncoder(&out)
+		enc.SetEscapeHTML(html)
+		require.NoError(t, enc.Encode(v))
+		require.Equal(t, strings.TrimSuffix(expect.String(), "\n"), out.String())
+	}
+
+	var out bytes.Buffer
+	enc := ffjson.NewEncoder(&out)
+	enc.SetEscaping(fflib.EscapeASCII)
+	enc.SetIndent("", " ")
+	require.NoError(t, enc.Encode(v))
+	require.Equal(t, "{\n \"X\": [\n  {\n   \"X\": \"\\u003ca\\u0026b\\u003e\\u00e9\"\n  }\n ]\n}", out.String())
+
+	enc = ffjson.NewEncoder(&out)
+	enc.SetEscaping(fflib.EscapeMinimal | fflib.RejectInvalidUTF8)
+	require.Equal(t, fflib.ErrInvalidUTF8, enc.Encode(&Xstring{X: "bad\xff"}))
+	out.Reset()
+	require.NoError(t, enc.Encode(&Xstring{X: "<ok>"}))
+	require.Equal(t, `{"X":"<ok>"}`, out.String())
+
+	enc.SetEscapeHTML(true)
+	require.Equal(t, fflib.ErrInvalidUTF8, enc.Encode(&Xstring{X: "bad\xff"}))
+}
+
+type maxWriteRecorder struct {
+	bytes.Buffer
+	maxSize int
+}
+
+func (w *maxWriteRecorder) Write(p []byte) (int, error) {
+	if len(p) > w.maxSize {
+		w.maxSize = len(p)
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestMarshalEncoderChunked(t *testing.T) {
+	slice := &XslicePtrStruct{}
+	m := &XMapStringString{X: map[string]string{}}
+	for i := 0; i < 5000; i++ {
+		slice.X = append(slice.X, &Xstring{X: fmt.Sprintf("value %d", i)})
+		m.X[fmt.Sprintf("key %d", i)] = fmt.Sprintf("value %d", i)
+	}
+
+	out := maxWriteRecorder{}
+	enc := ffjson.NewEncoder(&out)
+	enc.SetChunkSize(1024)
+
+	require.NoError(t, enc.Encode(slice))
+	expect, err := json.Marshal(slice)
+	require.NoError(t, err)
+	require.Equal(t, string(expect), out.String())
+	require.True(t, out.maxSize < 2048, "expected chunks of about 1024 bytes, got a write of %d", out.maxSize)
+
+	// Maps rewind the comma after the last entry.
+	out.Reset()
+	require.NoError(t, enc.Encode(m))
+	var back map[string]map[string]string
+	require.NoError(t, json.Unmarshal(out.Bytes(), &back))
+	require.Equal(t, m.X, back["X"])
+
+	enc.SetChunkSize(0)
+	out = maxWriteRecorder{}
+	require.NoError(t, enc.Encode(slice))
+	require.Equal(t, len(expect), out.maxSize)
+}
+
 func TestUnmarshalFaster(t *testing.T) {
 	buf := []byte(`{"id": 123213, "OriginID": 22, "meth": "GET"}`)
 	record := newLogFFRecord()
@@ -323,6 +487,10 @@ func testSameMarshal(t *testing.T, base interface{}, ff interface{}) {
 	}
 
 	require.Equal(t, string(bufbase), string(bufff), "json.Marshal of base[%T] != ff[%T]", base, ff)
+
+	bufff, err = ffjson.MarshalIndent(ff, " ", "  ")
+	require.NoError(t, err, "ff[%T] failed to MarshalIndent", ff)
+	require.Equal(t, string(bufbase), string(bufff), "json.MarshalIndent of base[%T] != ffjson.MarshalIndent of ff[%T]", base, ff)
 }
 
 func testCycle(t *testing.T, base interface{}, ff interface{}) {
@@ -427,6 +595,19 @@ func getXValue(thing interface{}) interface{} {
 	return buf.String()
 }
 
+func TestSkipUnknownFields(t *testing.T) {
+	record := Xstring{}
+
+	err := record.UnmarshalJSON([]byte(`{"unknown": {"a": [1, {"b": "]}\"["}, null], "c": {}}, "X": "v", "more": [[], "x"]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	if record.X != "v" {
+		t.Fatalf("record.X: expected: v got: %v", record.X)
+	}
+}
+
 func TestArray(t *testing.T) {
 	testType(t, &Tarray{X: [3]int{}}, &Xarray{X: [3]int{}})
 	testCycle(t, &Tarray{X: [3]int{42, -42, 44}}, &Xarray{X: [3]int{}})
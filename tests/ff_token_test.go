@@ -0,0 +1,229 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/pquerna/ffjson/ffjson"
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// stdTokens renders the tokens encoding/json's Decoder returns for input.
+func stdTokens(t *testing.T, input string) []string {
+	dec := json.NewDecoder(strings.NewReader(input))
+	dec.UseNumber()
+	var out []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out
+		}
+		require.NoError(t, err)
+		switch v := tok.(type) {
+		case json.Delim:
+			out = append(out, v.String())
+		case string:
+			out = append(out, "s:"+v)
+		case json.Number:
+			out = append(out, "n:"+v.String())
+		case bool:
+			out = append(out, fmt.Sprint(v))
+		case nil:
+			out = append(out, "null")
+		}
+	}
+}
+
+func ffTokens(t *testing.T, tr *ffjson.TokenReader) []string {
+	var out []string
+	for {
+		tok, err := tr.Token()
+		if err == io.EOF {
+			return out
+		}
+		require.NoError(t, err)
+		switch tok.Kind {
+		case ffjson.TokenKey, ffjson.TokenString:
+			out = append(out, "s:"+tok.String())
+		case ffjson.TokenNumber:
+			out = append(out, "n:"+tok.Number().String())
+		case ffjson.TokenBool:
+			out = append(out, fmt.Sprint(tok.Bool()))
+		default:
+			out = append(out, tok.String())
+		}
+	}
+}
+
+var tokenInputs = []string{
+	`{}`,
+	`[]`,
+	`{"a": 1, "b": [true, false, null], "c": {"d": "e\né"}, "f": -1.5e3}`,
+	`[[], {}, [{}], "x", 0]`,
+	`1 "two" [3] {"four": 4}`,
+	"  {\n\t\"k\" : [ 1 , 2 ]\n}  ",
+	`{"` + strings.Repeat("k", 5000) + `": "` + strings.Repeat("v", 20000) + `"}`,
+}
+
+func TestTokenReader(t *testing.T) {
+	for _, input := range tokenInputs {
+		expect := stdTokens(t, input)
+		require.Equal(t, expect, ffTokens(t, ffjson.NewTokenReaderBytes([]byte(input))), input)
+		require.Equal(t, expect, ffTokens(t, ffjson.NewTokenReader(iotest.OneByteReader(strings.NewReader(input)))), input)
+	}
+}
+
+func TestTokenReaderValues(t *testing.T) {
+	tr := ffjson.NewTokenReaderBytes([]byte(`[12, 1.5, "x"]`))
+
+	tok, err := tr.Token()
+	require.NoError(t, err)
+	require.Equal(t, ffjson.TokenArrayStart, tok.Kind)
+	require.Equal(t, 1, tr.InputOffset())
+
+	tok, err = tr.Token()
+	require.NoError(t, err)
+	i, err := tok.Int()
+	require.NoError(t, err)
+	require.Equal(t, int64(12), i)
+	require.Equal(t, 3, tr.InputOffset())
+
+	tok, err = tr.Token()
+	require.NoError(t, err)
+	f, err := tok.Float()
+	require.NoError(t, err)
+	require.Equal(t, 1.5, f)
+
+	tok, err = tr.Token()
+	require.NoError(t, err)
+	require.Equal(t, ffjson.TokenString, tok.Kind)
+	require.Equal(t, []byte("x"), tok.Bytes())
+	_, err = tok.Int()
+	require.Error(t, err)
+
+	require.False(t, tr.More())
+	tok, err = tr.Token()
+	require.NoError(t, err)
+	require.Equal(t, ffjson.TokenArrayEnd, tok.Kind)
+	require.Equal(t, 14, tr.InputOffset())
+}
+
+func TestTokenReaderMore(t *testing.T) {
+	tr := ffjson.NewTokenReaderBytes([]byte(`{"a": [1, 2], "b": 3}`))
+	_, err := tr.Token()
+	require.NoError(t, err)
+
+	var keys []string
+	for tr.More() {
+		tok, err := tr.Token()
+		require.NoError(t, err)
+		keys = append(keys, tok.String())
+		require.NoError(t, tr.Skip())
+	}
+	require.Equal(t, []string{"a", "b"}, keys)
+
+	tok, err := tr.Token()
+	require.NoError(t, err)
+	require.Equal(t, ffjson.TokenObjectEnd, tok.Kind)
+	require.False(t, tr.More())
+	_, err = tr.Token()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestTokenReaderDecode(t *testing.T) {
+	input := `{"items": [{"X": 1}, {"X": 2, "Y": [{}]}, {"X": 3}], "names": [{"X": "a"}], "n": 7}`
+	tr := ffjson.NewTokenReader(strings.NewReader(input))
+
+	_, err := tr.Token()
+	require.NoError(t, err)
+
+	var xs []int
+	var names []string
+	var n interface{}
+	tr.UseNumber()
+	for tr.More() {
+		key, err := tr.Token()
+		require.NoError(t, err)
+		switch key.String() {
+		case "items":
+			_, err = tr.Token()
+			require.NoError(t, err)
+			for tr.More() {
+				var v Xint
+				require.NoError(t, tr.Decode(&v))
+				xs = append(xs, v.X)
+			}
+			_, err = tr.Token()
+			require.NoError(t, err)
+		case "names":
+			var v []Tstring
+			require.NoError(t, tr.Decode(&v))
+			for _, s := range v {
+				names = append(names, s.X)
+			}
+		default:
+			require.NoError(t, tr.Decode(&n))
+		}
+	}
+	_, err = tr.Token()
+	require.NoError(t, err)
+	_, err = tr.Token()
+	require.Equal(t, io.EOF, err)
+
+	require.Equal(t, []int{1, 2, 3}, xs)
+	require.Equal(t, []string{"a"}, names)
+	require.Equal(t, json.Number("7"), n)
+}
+
+func TestTokenReaderErrors(t *testing.T) {
+	invalid := []string{
+		`{"a" 1}`,
+		`{"a": 1 "b": 2}`,
+		`[1,]`,
+		`{,}`,
+		`{1: 2}`,
+		`[}`,
+		`]`,
+		`[1 2]`,
+		`"\x"`,
+	}
+	for _, input := range invalid {
+		tr := ffjson.NewTokenReaderBytes([]byte(input))
+		var err error
+		for err == nil {
+			_, err = tr.Token()
+		}
+		require.NotEqual(t, io.EOF, err, input)
+		require.IsType(t, &fflib.LexerError{}, err, input)
+	}
+
+	tr := ffjson.NewTokenReader(bytes.NewReader([]byte(`{"a": [1`)))
+	var err error
+	for err == nil {
+		_, err = tr.Token()
+	}
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
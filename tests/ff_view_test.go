@@ -0,0 +1,117 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tff
+
+import (
+	"encoding/json"
+	"testing"
+
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func newXView() *XView {
+	x := &XView{
+		ID:     1,
+		Email:  "a@example.com",
+		Notes:  "notes",
+		Inner:  XViewInner{Public: "p", Secret: "s"},
+		PInner: &XViewInner{Public: "pp", Secret: "ps"},
+		List:   []XViewInner{{Public: "l", Secret: "ls"}},
+		ByName: map[string]*XViewInner{"k": {Public: "m", Secret: "ms"}, "nil": nil},
+		ByKey:  map[XViewKey]*XViewInner{"key": {Public: "n", Secret: "ns"}},
+		Last:   true,
+	}
+	x.Anon.Public = "a"
+	x.Anon.Secret = "as"
+	return x
+}
+
+func TestMarshalJSONView(t *testing.T) {
+	inner := func(public, secret string, admin bool) map[string]interface{} {
+		v := map[string]interface{}{"public": public}
+		if admin {
+			v["secret"] = secret
+		}
+		return v
+	}
+	expect := func(view string) map[string]interface{} {
+		admin := view == "admin"
+		v := map[string]interface{}{
+			"id":     1.0,
+			"inner":  inner("p", "s", admin),
+			"pinner": inner("pp", "ps", admin),
+			"list":   []interface{}{inner("l", "ls", admin)},
+			"byName": map[string]interface{}{"k": inner("m", "ms", admin), "nil": nil},
+			"byKey":  map[string]interface{}{"key": inner("n", "ns", admin)},
+			"anon":   inner("a", "as", admin),
+		}
+		if admin || view == "internal" {
+			v["email"] = "a@example.com"
+		}
+		if view == "internal" {
+			v["notes"] = "notes"
+		}
+		if admin {
+			v["last"] = true
+		}
+		return v
+	}
+
+	x := newXView()
+	for _, view := range []string{"", "public", "admin", "internal"} {
+		var buf fflib.Buffer
+		require.NoError(t, x.MarshalJSONView(&buf, view))
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got), buf.String())
+		require.Equal(t, expect(view), got, "view %q: %s", view, buf.String())
+	}
+
+	// Other encoders are not affected by the views.
+	data, err := x.MarshalJSON()
+	require.NoError(t, err)
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, "notes", got["notes"])
+	require.Equal(t, "s", got["inner"].(map[string]interface{})["secret"])
+}
+
+func TestMarshalJSONViewEmpty(t *testing.T) {
+	var buf fflib.Buffer
+	require.NoError(t, (&XViewInner{}).MarshalJSONView(&buf, "admin"))
+	require.Equal(t, `{ "public":"","secret":""}`, buf.String())
+
+	buf.Reset()
+	var x *XView
+	require.NoError(t, x.MarshalJSONView(&buf, "admin"))
+	require.Equal(t, `null`, buf.String())
+}
+
+func TestMarshalJSONViewWithoutViews(t *testing.T) {
+	x := &XMergeInner{A: "a", B: 1}
+	var buf fflib.Buffer
+	require.NoError(t, x.MarshalJSONView(&buf, "admin"))
+	out, err := x.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, string(out), buf.String())
+
+	buf.Reset()
+	var nilx *XMergeInner
+	require.NoError(t, nilx.MarshalJSONView(&buf, "admin"))
+	require.Equal(t, `null`, buf.String())
+}
@@ -27,6 +27,9 @@ func FuzzGenerate(fuzz []byte) int {
 		"",
 		true,
 		true,
+		false,
+		"",
+		"",
 	)
 	if err != nil {
 		return 0
@@ -18,12 +18,16 @@
 package goser
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/pquerna/ffjson/ffjson"
 	base "github.com/pquerna/ffjson/tests/goser/base"
 	ff "github.com/pquerna/ffjson/tests/goser/ff"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestRoundTrip(t *testing.T) {
@@ -144,3 +148,43 @@ func TestUnmarshal(t *testing.T) {
 		t.Fatalf("Expected: %v\n Got: %v\n from: %s", rec2, rec, string(buf))
 	}
 }
+
+func TestDecodeReader(t *testing.T) {
+	buf := getBaseData(t)
+
+	rec := ff.Log{}
+	err := ffjson.NewDecoder().DecodeReader(iotest.OneByteReader(bytes.NewReader(buf)), &rec)
+	if err != nil {
+		t.Fatalf("DecodeReader: %v from %s", err, string(buf))
+	}
+
+	rec2 := ff.Log{}
+	err = rec2.UnmarshalJSON(buf)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	if !reflect.DeepEqual(rec, rec2) {
+		t.Fatalf("Expected: %v\n Got: %v\n from: %s", rec2, rec, string(buf))
+	}
+}
+
+func TestDecodeReaderError(t *testing.T) {
+	buf := getBaseData(t)
+	buf = bytes.Replace(buf, []byte(`"timestamp"`), []byte(`"timestamp" 1`), 1)
+
+	rec := ff.Log{}
+	errReader := ffjson.NewDecoder().DecodeReader(iotest.HalfReader(bytes.NewReader(buf)), &rec)
+	errBytes := rec.UnmarshalJSON(buf)
+	if errReader == nil || errBytes == nil {
+		t.Fatalf("expected errors, got: %v and %v", errReader, errBytes)
+	}
+	if errReader.Error() != errBytes.Error() {
+		t.Fatalf("expected the same error from a reader and bytes, got: %v and %v", errReader, errBytes)
+	}
+
+	errReader = ffjson.NewDecoder().DecodeReader(iotest.TimeoutReader(iotest.OneByteReader(bytes.NewReader(getBaseData(t)))), &rec)
+	if errReader == nil || !strings.Contains(errReader.Error(), iotest.ErrTimeout.Error()) {
+		t.Fatalf("expected the read error, got: %v", errReader)
+	}
+}
@@ -19,6 +19,7 @@ package ff
 
 import (
 	"encoding/json"
+	"math/big"
 )
 
 // Number struct
@@ -32,3 +33,31 @@ func NewNumber(e *Number) {
 	e.Int = "1"
 	e.Float = "3.14"
 }
+
+// NumberPtr struct
+type NumberPtr struct {
+	Int    *json.Number
+	Quoted json.Number `json:",string"`
+}
+
+// BigNumber struct
+type BigNumber struct {
+	Int   *big.Int
+	Float *big.Float
+	Rat   *big.Rat
+	Ints  []*big.Int
+}
+
+// NewBigNumber creates a new big number
+func NewBigNumber(e *BigNumber) {
+	e.Int, _ = new(big.Int).SetString("123456789012345678901234567890", 10)
+	e.Float, _ = new(big.Float).SetString("1.5e400")
+	e.Rat = big.NewRat(1, 3)
+	e.Ints = []*big.Int{big.NewInt(-1), nil, big.NewInt(1)}
+}
+
+// Any struct
+type Any struct {
+	Value interface{}
+	Map   map[string]interface{}
+}
@@ -15,13 +15,14 @@
  *
  */
 
-package types
+package number
 
 import (
 	"encoding/json"
 	"reflect"
 	"testing"
 
+	"github.com/pquerna/ffjson/ffjson"
 	ff "github.com/pquerna/ffjson/tests/number/ff"
 )
 
@@ -68,3 +69,169 @@ func TestUnmarshalFull(t *testing.T) {
 		t.Fatalf("UnmarshalJSON: %v", err)
 	}
 }
+
+func TestNumberUnmarshalTokens(t *testing.T) {
+	record := ff.Number{}
+	err := record.UnmarshalJSON([]byte(`{"Int": 12345678901234567890123, "Float": "-1.5e-7"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if record.Int != "12345678901234567890123" || record.Float != "-1.5e-7" {
+		t.Fatalf("Unexpected values: %#v", record)
+	}
+
+	err = record.UnmarshalJSON([]byte(`{"Int": "12a"}`))
+	if err == nil {
+		t.Fatalf("Expected error for invalid number string")
+	}
+
+	err = record.UnmarshalJSON([]byte(`{"Int": true}`))
+	if err == nil {
+		t.Fatalf("Expected error for bool")
+	}
+}
+
+func TestNumberMarshal(t *testing.T) {
+	record := ff.NumberPtr{}
+	buf, err := record.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected, err := json.Marshal(&struct {
+		Int    *json.Number
+		Quoted json.Number `json:",string"`
+	}{})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(buf) != string(expected) {
+		t.Fatalf("Expected: %s\n Got: %s", expected, buf)
+	}
+
+	n := json.Number("1e3")
+	record.Int = &n
+	record.Quoted = "12"
+	buf, err = record.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if string(buf) != `{"Int":1e3,"Quoted":"12"}` {
+		t.Fatalf("Unexpected output: %s", buf)
+	}
+
+	var tripped ff.NumberPtr
+	err = tripped.UnmarshalJSON(buf)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !reflect.DeepEqual(record, tripped) {
+		t.Fatalf("Expected: %v\n Got: %v", record, tripped)
+	}
+
+	record.Quoted = "0x12"
+	_, err = record.MarshalJSON()
+	if err == nil {
+		t.Fatalf("Expected error for invalid number")
+	}
+}
+
+func TestBigNumberSameMarshal(t *testing.T) {
+	var record ff.BigNumber
+	ff.NewBigNumber(&record)
+
+	buf1, err := json.Marshal(&record)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	type base ff.BigNumber
+	buf2, err := json.Marshal((*base)(&record))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if string(buf1) != string(buf2) {
+		t.Fatalf("Expected: %s\n Got: %s", buf2, buf1)
+	}
+}
+
+func TestBigNumberRoundTrip(t *testing.T) {
+	var record ff.BigNumber
+	var recordTripped ff.BigNumber
+	ff.NewBigNumber(&record)
+
+	buf, err := json.Marshal(&record)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	err = recordTripped.UnmarshalJSON(buf)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	if record.Int.Cmp(recordTripped.Int) != 0 ||
+		record.Float.Cmp(recordTripped.Float) != 0 ||
+		record.Rat.Cmp(recordTripped.Rat) != 0 {
+		t.Fatalf("Expected: %v\n Got: %v", record, recordTripped)
+	}
+	if len(recordTripped.Ints) != 3 || recordTripped.Ints[1] != nil || recordTripped.Ints[2].Int64() != 1 {
+		t.Fatalf("Unexpected Ints: %v", recordTripped.Ints)
+	}
+}
+
+func TestBigNumberUnmarshalTokens(t *testing.T) {
+	record := ff.BigNumber{}
+	err := record.UnmarshalJSON([]byte(`{"Int": 98765432109876543210, "Float": 2.5e-400, "Rat": 0.25}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if record.Int.String() != "98765432109876543210" {
+		t.Fatalf("Unexpected Int: %v", record.Int)
+	}
+	if record.Float.Text('g', 5) != "2.5e-400" {
+		t.Fatalf("Unexpected Float: %v", record.Float)
+	}
+	if record.Rat.RatString() != "1/4" {
+		t.Fatalf("Unexpected Rat: %v", record.Rat)
+	}
+
+	err = record.UnmarshalJSON([]byte(`{"Int": null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if record.Int != nil {
+		t.Fatalf("Expected nil Int, got: %v", record.Int)
+	}
+
+	err = record.UnmarshalJSON([]byte(`{"Int": 1.5}`))
+	if err == nil {
+		t.Fatalf("Expected error for non-integer big.Int")
+	}
+}
+
+func TestUseNumber(t *testing.T) {
+	input := []byte(`{"Value": 12345678901234567890, "Map": {"a": 1.5}}`)
+
+	record := ff.Any{}
+	err := ffjson.NewDecoder().Decode(input, &record)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if _, ok := record.Value.(float64); !ok {
+		t.Fatalf("Expected float64, got: %T", record.Value)
+	}
+
+	dec := ffjson.NewDecoder()
+	dec.UseNumber()
+	err = dec.Decode(input, &record)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if record.Value != json.Number("12345678901234567890") {
+		t.Fatalf("Expected json.Number, got: %T %v", record.Value, record.Value)
+	}
+	if record.Map["a"] != json.Number("1.5") {
+		t.Fatalf("Expected json.Number, got: %T %v", record.Map["a"], record.Map["a"])
+	}
+}
@@ -0,0 +1,35 @@
+/**
+ *  Copyright 2016 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"github.com/pquerna/ffjson/tests/codec"
+)
+
+// Registry has codec.Cents in all places the codec registered with
+// -codecs applies.
+type Registry struct {
+	Price   codec.Cents             `json:"price"`
+	Opt     *codec.Cents            `json:"opt"`
+	Prices  []codec.Cents           `json:"prices"`
+	PPrices []*codec.Cents          `json:"pprices"`
+	ByName  map[string]codec.Cents  `json:"byName"`
+	PByName map[string]*codec.Cents `json:"pbyName"`
+	Fixed   [2]codec.Cents          `json:"fixed"`
+	Any     interface{}             `json:"any"`
+}
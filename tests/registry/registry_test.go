@@ -0,0 +1,151 @@
+/**
+ *  Copyright 2016 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package registry
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pquerna/ffjson/ffjson"
+	fflib "github.com/pquerna/ffjson/fflib/v1"
+	"github.com/pquerna/ffjson/tests/codec"
+	ff "github.com/pquerna/ffjson/tests/registry/ff"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	fflib.RegisterCodec(codec.Cents(0), codec.CentsCodec)
+}
+
+func cents(c codec.Cents) *codec.Cents {
+	return &c
+}
+
+func TestRegistryMarshal(t *testing.T) {
+	r := ff.Registry{
+		Price:   1234,
+		Prices:  []codec.Cents{1, -250},
+		PPrices: []*codec.Cents{cents(5), nil},
+		ByName:  map[string]codec.Cents{"a": 100},
+		PByName: map[string]*codec.Cents{"b": nil},
+		Fixed:   [2]codec.Cents{7, 8},
+		Any:     codec.Cents(99),
+	}
+	out, err := ffjson.Marshal(&r)
+	require.NoError(t, err)
+	require.Equal(t, `{"price":"12.34","opt":null,"prices":["0.01","-2.50"],"pprices":["0.05",null],`+
+		`"byName":{ "a":"1.00"},"pbyName":{ "b":null},"fixed":["0.07","0.08"],"any":"0.99"}`, string(out))
+}
+
+func TestRegistryUnmarshal(t *testing.T) {
+	var r ff.Registry
+	err := ffjson.Unmarshal([]byte(`{"price":"12.34","opt":1.5,"prices":["0.01",2],"pprices":[null,"0.05"],`+
+		`"byName":{"a":"1.00"},"pbyName":{"b":"3"},"fixed":["0.07","0.08"],"any":"0.99"}`), &r)
+	require.NoError(t, err)
+	require.Equal(t, ff.Registry{
+		Price:   1234,
+		Opt:     cents(150),
+		Prices:  []codec.Cents{1, 200},
+		PPrices: []*codec.Cents{nil, cents(5)},
+		ByName:  map[string]codec.Cents{"a": 100},
+		PByName: map[string]*codec.Cents{"b": cents(300)},
+		Fixed:   [2]codec.Cents{7, 8},
+		Any:     "0.99",
+	}, r)
+
+	err = ffjson.Unmarshal([]byte(`{"price":true}`), &r)
+	require.Error(t, err)
+}
+
+func TestRegistryRuntime(t *testing.T) {
+	out, err := ffjson.Marshal(codec.Cents(5))
+	require.NoError(t, err)
+	require.Equal(t, `"0.05"`, string(out))
+
+	out, err = ffjson.MarshalAppend([]byte("x"), codec.Cents(5))
+	require.NoError(t, err)
+	require.Equal(t, `x"0.05"`, string(out))
+
+	out, err = ffjson.MarshalIndent([]codec.Cents{5}, "", " ")
+	require.NoError(t, err)
+	require.Equal(t, "[\n 5\n]", string(out), "only the registered type itself")
+
+	out, err = ffjson.MarshalIndent(codec.Cents(5), "", " ")
+	require.NoError(t, err)
+	require.Equal(t, `"0.05"`, string(out))
+
+	var c codec.Cents
+	require.NoError(t, ffjson.Unmarshal([]byte(` "1.5" `), &c))
+	require.Equal(t, codec.Cents(150), c)
+
+	out, err = ffjson.Marshal(&c)
+	require.NoError(t, err)
+	require.Equal(t, `"1.50"`, string(out), "round trip through a pointer")
+
+	out, err = ffjson.Marshal((*codec.Cents)(nil))
+	require.NoError(t, err)
+	require.Equal(t, `null`, string(out))
+
+	require.Error(t, ffjson.Unmarshal([]byte(`"1.5" 2`), &c))
+	require.Error(t, ffjson.Unmarshal([]byte(`{}`), &c))
+}
+
+func TestRegistryDiff(t *testing.T) {
+	a := ff.Registry{Price: 1, Prices: []codec.Cents{1}}
+	b := ff.Registry{
+		Price:   1234,
+		Opt:     cents(5),
+		Prices:  []codec.Cents{1, -250},
+		PPrices: []*codec.Cents{nil},
+		ByName:  map[string]codec.Cents{"a": 100},
+		Fixed:   [2]codec.Cents{7, 8},
+		Any:     codec.Cents(99),
+	}
+	out, err := fflib.MarshalPatch(a.DiffJSON(&b))
+	require.NoError(t, err)
+	require.Equal(t, `[{"op":"replace","path":"/price","value":"12.34"},`+
+		`{"op":"replace","path":"/opt","value":"0.05"},`+
+		`{"op":"replace","path":"/prices","value":["0.01","-2.50"]},`+
+		`{"op":"replace","path":"/pprices","value":[null]},`+
+		`{"op":"replace","path":"/byName","value":{ "a":"1.00"}},`+
+		`{"op":"replace","path":"/fixed","value":["0.07","0.08"]},`+
+		`{"op":"replace","path":"/any","value":"0.99"}]`, string(out))
+
+	out, err = fflib.MarshalPatch(b.DiffJSON(&ff.Registry{Prices: b.Prices}))
+	require.NoError(t, err)
+	require.Contains(t, string(out), `{"op":"replace","path":"/opt","value":null}`)
+}
+
+func TestRegistryStream(t *testing.T) {
+	var buf bytes.Buffer
+	enc := ffjson.NewEncoder(&buf)
+	c := codec.Cents(5)
+	require.NoError(t, enc.Encode(c))
+	require.NoError(t, enc.Encode(&c))
+	require.Equal(t, `"0.05""0.05"`, buf.String())
+
+	dec := ffjson.NewDecoder()
+	var got codec.Cents
+	require.NoError(t, dec.Decode([]byte(`"1.25" `), &got))
+	require.Equal(t, codec.Cents(125), got)
+	require.Error(t, dec.Decode([]byte(`"1.25" 2`), &got))
+
+	require.NoError(t, dec.DecodeReader(strings.NewReader(`"2"`), &got))
+	require.Equal(t, codec.Cents(200), got)
+}
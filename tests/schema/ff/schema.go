@@ -0,0 +1,88 @@
+/**
+ *  Copyright 2016 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Document is a stored document.
+type Document struct {
+	// ID identifies the document.
+	ID      int64                  `json:"id,string"`
+	Title   string                 `json:"title"` // Title shown in lists.
+	Tags    []string               `json:"tags,omitempty"`
+	Size    uint32                 `json:"size"`
+	Score   float64                `json:"score,omitempty"`
+	Draft   *bool                  `json:"draft"`
+	Data    []byte                 `json:"data,omitempty"`
+	Created time.Time              `json:"created"`
+	Author  *Author                `json:"author,omitempty"`
+	Editors []Author               `json:"editors"`
+	Parent  *Document              `json:"parent,omitempty"`
+	Attrs   map[string]interface{} `json:"attrs"`
+	Point   [2]float64             `json:"point"`
+	Meta    struct {
+		Rev int `json:"rev"`
+	} `json:"meta"`
+	Audit
+}
+
+// Author of a document.
+type Author struct {
+	Name string `json:"name"`
+	// Email address, if public.
+	Email string `json:"email,omitempty"`
+}
+
+// Audit records changes.
+type Audit struct {
+	// ModifiedBy is the last editor.
+	ModifiedBy string `json:"modifiedBy"`
+}
+
+// Event is something that happened.
+//
+// Events are never changed.
+type Event struct {
+	Kind  string          `json:"event-kind"`
+	Count *uint64         `json:"count,string"`
+	Doc   Document        `json:"doc"`
+	Raw   json.RawMessage `json:"raw"`
+}
+
+// NewDocument creates a document using every field
+func NewDocument(e *Document) {
+	draft := true
+	e.ID = 42
+	e.Title = "<Title>"
+	e.Tags = []string{"a", "b"}
+	e.Size = 7
+	e.Score = 0.5
+	e.Draft = &draft
+	e.Data = []byte("data")
+	e.Created = time.Date(2014, 1, 2, 3, 4, 5, 0, time.UTC)
+	e.Author = &Author{Name: "author", Email: "a@example.com"}
+	e.Editors = []Author{{Name: "editor"}}
+	e.Parent = &Document{Title: "parent"}
+	e.Attrs = map[string]interface{}{"k": []interface{}{1.0, "v"}}
+	e.Point = [2]float64{1, 2}
+	e.Meta.Rev = 3
+	e.ModifiedBy = "editor"
+}
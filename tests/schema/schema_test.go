@@ -0,0 +1,193 @@
+/**
+ *  Copyright 2016 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package schema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	ff "github.com/pquerna/ffjson/tests/schema/ff"
+	"github.com/stretchr/testify/require"
+)
+
+func loadSchema(t *testing.T, name string) map[string]interface{} {
+	data, err := ioutil.ReadFile("ff/schema_" + name + ".schema.json")
+	require.NoError(t, err)
+	var s map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &s))
+	return s
+}
+
+// validate checks v against the keywords of JSON Schema that ffjson
+// generates, and returns the JSON Pointer of the first value that does not
+// match, or "" if v is valid.
+func validate(root, s map[string]interface{}, v interface{}, path string) string {
+	if ref, ok := s["$ref"].(string); ok {
+		target := root
+		for _, seg := range strings.Split(ref, "/")[1:] {
+			target = target[seg].(map[string]interface{})
+		}
+		if bad := validate(root, target, v, path); bad != "" {
+			return bad
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		valid := false
+		for _, sub := range anyOf {
+			valid = valid || validate(root, sub.(map[string]interface{}), v, path) == ""
+		}
+		if !valid {
+			return path
+		}
+	}
+
+	if typ, ok := s["type"]; ok {
+		types, ok := typ.([]interface{})
+		if !ok {
+			types = []interface{}{typ}
+		}
+		valid := false
+		for _, typ := range types {
+			valid = valid || hasType(v, typ.(string))
+		}
+		if !valid {
+			return path
+		}
+	}
+	if min, ok := s["minimum"].(float64); ok && v.(float64) < min {
+		return path
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		props, _ := s["properties"].(map[string]interface{})
+		required, _ := s["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				return path + "/" + name.(string)
+			}
+		}
+		for name, value := range v {
+			sub, ok := props[name].(map[string]interface{})
+			if !ok {
+				sub, _ = s["additionalProperties"].(map[string]interface{})
+			}
+			if bad := validate(root, sub, value, path+"/"+name); bad != "" {
+				return bad
+			}
+		}
+	case []interface{}:
+		if n, ok := s["minItems"].(float64); ok && len(v) < int(n) {
+			return path
+		}
+		if n, ok := s["maxItems"].(float64); ok && len(v) > int(n) {
+			return path
+		}
+		items, _ := s["items"].(map[string]interface{})
+		for _, value := range v {
+			if bad := validate(root, items, value, path+"/-"); bad != "" {
+				return bad
+			}
+		}
+	}
+	return ""
+}
+
+func hasType(v interface{}, typ string) bool {
+	switch v := v.(type) {
+	case nil:
+		return typ == "null"
+	case bool:
+		return typ == "boolean"
+	case string:
+		return typ == "string"
+	case float64:
+		return typ == "number" || (typ == "integer" && v == float64(int64(v)))
+	case []interface{}:
+		return typ == "array"
+	case map[string]interface{}:
+		return typ == "object"
+	}
+	return false
+}
+
+func TestSchema(t *testing.T) {
+	s := loadSchema(t, "Document")
+	require.Equal(t, "https://json-schema.org/draft/2020-12/schema", s["$schema"])
+	require.Equal(t, "Document", s["title"])
+	require.Equal(t, "Document is a stored document.", s["description"])
+	require.Equal(t, []interface{}{"id", "title", "size", "draft", "created", "editors",
+		"attrs", "point", "meta", "modifiedBy"}, s["required"])
+
+	props := s["properties"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{
+		"type": "string", "description": "ID identifies the document.",
+	}, props["id"])
+	require.Equal(t, map[string]interface{}{
+		"type": "string", "description": "Title shown in lists.",
+	}, props["title"])
+	require.Equal(t, map[string]interface{}{
+		"type": "string", "description": "ModifiedBy is the last editor.",
+	}, props["modifiedBy"])
+	require.Equal(t, map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"$ref": "#"},
+			map[string]interface{}{"type": "null"},
+		},
+	}, props["parent"])
+
+	author := s["$defs"].(map[string]interface{})["Author"].(map[string]interface{})
+	require.Equal(t, "Author of a document.", author["description"])
+	require.Equal(t, []interface{}{"name"}, author["required"])
+
+	audit := loadSchema(t, "Audit")
+	require.Nil(t, audit["$defs"])
+	require.Equal(t, "object", audit["type"])
+}
+
+func TestSchemaValidates(t *testing.T) {
+	s := loadSchema(t, "Document")
+
+	var full ff.Document
+	ff.NewDocument(&full)
+	for _, doc := range []*ff.Document{&full, full.Parent, {}} {
+		data, err := doc.MarshalJSON()
+		require.NoError(t, err)
+		var v interface{}
+		require.NoError(t, json.Unmarshal(data, &v))
+		require.Equal(t, "", validate(s, s, v, ""), string(data))
+	}
+
+	data, err := full.MarshalJSON()
+	require.NoError(t, err)
+	for path, change := range map[string]func(map[string]interface{}){
+		"/title":     func(v map[string]interface{}) { delete(v, "title") },
+		"/size":      func(v map[string]interface{}) { v["size"] = -1.0 },
+		"/id":        func(v map[string]interface{}) { v["id"] = 42.0 },
+		"/point":     func(v map[string]interface{}) { v["point"] = []interface{}{1.0} },
+		"/editors/-": func(v map[string]interface{}) { v["editors"] = []interface{}{"name"} },
+		"/parent":    func(v map[string]interface{}) { v["parent"].(map[string]interface{})["meta"] = nil },
+	} {
+		var v map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &v))
+		change(v)
+		require.Equal(t, path, validate(s, s, v, ""))
+	}
+}
@@ -0,0 +1,84 @@
+/**
+ *  Copyright 2016 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package schema
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const expectTypeScript = `// Code generated by ffjson <https://github.com/pquerna/ffjson>. DO NOT EDIT.
+// source: tests/schema/ff/schema.go
+
+/** Audit records changes. */
+export interface Audit {
+  /** ModifiedBy is the last editor. */
+  modifiedBy: string;
+}
+
+/** Author of a document. */
+export interface Author {
+  name: string;
+  /** Email address, if public. */
+  email?: string;
+}
+
+/** Document is a stored document. */
+export interface Document {
+  /** ID identifies the document. */
+  id: string;
+  /** Title shown in lists. */
+  title: string;
+  tags?: string[];
+  size: number;
+  score?: number;
+  draft: boolean | null;
+  data?: string;
+  created: string;
+  author?: Author;
+  editors: Author[] | null;
+  parent?: Document;
+  attrs: Record<string, unknown> | null;
+  point: number[];
+  meta: {
+    rev: number;
+  };
+  /** ModifiedBy is the last editor. */
+  modifiedBy: string;
+}
+
+/**
+ * Event is something that happened.
+ *
+ * Events are never changed.
+ */
+export interface Event {
+  "event-kind": string;
+  count: string | null;
+  doc: Document;
+  raw: unknown;
+}
+`
+
+func TestTypeScript(t *testing.T) {
+	data, err := ioutil.ReadFile("ff/schema.d.ts")
+	require.NoError(t, err)
+	require.Equal(t, expectTypeScript, string(data))
+}